@@ -0,0 +1,298 @@
+package privy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrAuditDisabled = errors.New("audit log is not enabled")
+	ErrAuditFailed   = errors.New("failed to record audit entry")
+)
+
+// Audit operations
+const (
+	AuditCreateResource    = "create_resource"
+	AuditCreateResources   = "create_resources"
+	AuditAddActions        = "add_actions"
+	AuditDeleteResource    = "delete_resource"
+	AuditSyncResources     = "sync_resources"
+	AuditCreateRole        = "create_role"
+	AuditAssignPermissions = "assign_permissions"
+	AuditRemovePermissions = "remove_permissions"
+	AuditAddRoleParents    = "add_role_parents"
+	AuditRemoveRoleParents = "remove_role_parents"
+	AuditDeleteRole        = "delete_role"
+	AuditBindRole          = "bind_role"
+	AuditUnbindRole        = "unbind_role"
+	AuditImport            = "import"
+)
+
+// Audit target types
+const (
+	// AuditTargetResource targets a resource by its path. SyncResources targets the whole
+	// resource tree with an empty path.
+	AuditTargetResource = "resource"
+
+	// AuditTargetRole targets a role by its key
+	AuditTargetRole = "role"
+
+	// AuditTargetSubject targets the role bindings of a subject by its ID
+	AuditTargetSubject = "subject"
+
+	// AuditTargetPolicy targets the whole policy, i.e. all resources and roles
+	AuditTargetPolicy = "policy"
+)
+
+// AuditEntry records a change made through the Manager. Before and After hold JSON snapshots
+// of the target: a PolicyResource for resources, a PolicyRole for roles, the bound role keys for
+// subjects and a PolicyDocument for policies. A missing snapshot means the target did not
+// exist before or after the change.
+type AuditEntry struct {
+	ID         uint            `gorm:"primarykey" json:"id"`
+	Time       time.Time       `gorm:"index;not null" json:"time"`
+	Actor      string          `gorm:"index" json:"actor"`
+	Operation  string          `gorm:"not null" json:"operation"`
+	TargetType string          `gorm:"index:idx_audit_target;not null" json:"target_type"`
+	Target     string          `gorm:"index:idx_audit_target" json:"target"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+}
+
+// AuditQuery selects audit entries. Empty fields match everything.
+type AuditQuery struct {
+	TargetType string
+	Target     string
+	Actor      string
+	// Since is the inclusive start of the time range
+	Since time.Time
+	// Until is the exclusive end of the time range
+	Until time.Time
+	// Limit bounds the number of entries returned; zero means no limit
+	Limit int
+}
+
+// AuditStore persists audit entries
+type AuditStore interface {
+	// WithContext returns an AuditStore running all operations with the given context
+	WithContext(ctx context.Context) AuditStore
+
+	// Record stores an entry and sets its ID
+	Record(entry *AuditEntry) error
+
+	// Query lists the entries matching the query, oldest first
+	Query(query AuditQuery) ([]AuditEntry, error)
+
+	// Initialize creates necessary tables/schemas
+	Initialize() error
+}
+
+// WithAuditStore records every change made through the Manager in the given store. Entries are
+// recorded after the change has been committed. If recording fails, the change is durable and
+// the method returns an error wrapping ErrAuditFailed together with its usual result, e.g. the
+// created role, so callers must not retry the change. Changes that leave the target unchanged,
+// such as assigning a permission the role already has, are not recorded.
+func WithAuditStore(store AuditStore) ManagerOption {
+	return func(m *Manager) {
+		m.audit = store
+	}
+}
+
+type actorKey struct{}
+
+// WithActor returns a context carrying the actor recorded in audit entries, e.g. a user ID
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor set with WithActor, or an empty string
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// QueryAudit lists the audit entries matching the query, oldest first.
+// It returns ErrAuditDisabled unless an audit store is configured with WithAuditStore.
+// It uses context.Background internally; to specify the context, use QueryAuditContext.
+func (m *Manager) QueryAudit(query AuditQuery) ([]AuditEntry, error) {
+	return m.QueryAuditContext(context.Background(), query)
+}
+
+// QueryAuditContext is like QueryAudit but runs storage operations with the given context
+func (m *Manager) QueryAuditContext(ctx context.Context, query AuditQuery) ([]AuditEntry, error) {
+	if m.audit == nil {
+		return nil, ErrAuditDisabled
+	}
+
+	return m.audit.WithContext(ctx).Query(query)
+}
+
+// change describes the target of a write and how to take a snapshot of it
+type change struct {
+	operation  string
+	targetType string
+	target     string
+	snapshot   func(s Storage) (any, error)
+	// invalidate drops cached roles once the transaction has finished, since the write
+	// may change the permissions of roles
+	invalidate bool
+}
+
+// write runs fn in a transaction. Once the transaction is committed, it publishes the events
+// of the change and records an audit entry.
+func (m *Manager) write(ctx context.Context, c change, fn func(s Storage) error) error {
+	var entry *AuditEntry
+	var events []Event
+	err := m.storage.WithContext(ctx).WithTx(func(s Storage) error {
+		if m.events != nil {
+			es := newEventStorage(s)
+			defer func() { events = es.flush() }()
+			s = es
+		}
+
+		if m.audit == nil {
+			return fn(s)
+		}
+
+		before, err := takeSnapshot(s, c)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(s); err != nil {
+			return err
+		}
+
+		after, err := takeSnapshot(s, c)
+		if err != nil {
+			return err
+		}
+
+		if !bytes.Equal(before, after) {
+			entry = &AuditEntry{
+				Actor:      ActorFromContext(ctx),
+				Operation:  c.operation,
+				TargetType: c.targetType,
+				Target:     c.target,
+				Before:     before,
+				After:      after,
+			}
+		}
+		return nil
+	})
+	// Subscribers must not see permissions cached before the change
+	if c.invalidate {
+		m.invalidateRoles()
+	}
+	if err != nil {
+		return err
+	}
+
+	if m.events != nil {
+		m.publish(ctx, events)
+	}
+
+	if entry == nil {
+		return nil
+	}
+
+	entry.Time = time.Now()
+	if err := m.audit.WithContext(ctx).Record(entry); err != nil {
+		return fmt.Errorf("%w: %v", ErrAuditFailed, err)
+	}
+
+	return nil
+}
+
+// writeResult is like write for changes that produce a result. The change is committed even if
+// it could not be audited, so the result is returned together with ErrAuditFailed.
+func writeResult[T any](ctx context.Context, m *Manager, c change, fn func(s Storage) (T, error)) (T, error) {
+	var result T
+	err := m.write(ctx, c, func(s Storage) error {
+		var err error
+		result, err = fn(s)
+		return err
+	})
+	if err != nil && !errors.Is(err, ErrAuditFailed) {
+		var zero T
+		return zero, err
+	}
+
+	return result, err
+}
+
+// takeSnapshot encodes a snapshot of the target, or returns nil if it does not exist
+func takeSnapshot(s Storage, c change) (json.RawMessage, error) {
+	v, err := c.snapshot(s)
+	if err != nil || v == nil {
+		return nil, err
+	}
+
+	return json.Marshal(v)
+}
+
+// resourceChange describes a write to the resource at path
+func (m *Manager) resourceChange(operation, path string) change {
+	return change{
+		operation:  operation,
+		targetType: AuditTargetResource,
+		target:     path,
+		snapshot: func(s Storage) (any, error) {
+			resource, err := m.getResourceByPath(s, path)
+			if err != nil {
+				if err == ErrResourceNotFound {
+					return nil, nil
+				}
+				return nil, err
+			}
+
+			return exportResource(s, *resource)
+		},
+	}
+}
+
+// roleChange describes a write to the role with the given key
+func roleChange(operation, key string) change {
+	return change{
+		operation:  operation,
+		targetType: AuditTargetRole,
+		target:     key,
+		invalidate: true,
+		snapshot: func(s Storage) (any, error) {
+			role, err := s.GetRole(key)
+			if err != nil {
+				if err == ErrRoleNotFound {
+					return nil, nil
+				}
+				return nil, err
+			}
+
+			return exportRole(*role), nil
+		},
+	}
+}
+
+// subjectChange describes a write to the role bindings of a subject
+func (m *Manager) subjectChange(operation, subjectID string) change {
+	return change{
+		operation:  operation,
+		targetType: AuditTargetSubject,
+		target:     subjectID,
+		snapshot: func(s Storage) (any, error) {
+			roles, err := m.listSubjectRoles(s, subjectID)
+			if err != nil {
+				return nil, err
+			}
+
+			keys := make([]string, 0, len(roles))
+			for _, role := range roles {
+				keys = append(keys, role.Key)
+			}
+			return keys, nil
+		},
+	}
+}
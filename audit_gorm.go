@@ -0,0 +1,61 @@
+package privy
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// GormAuditStore implements AuditStore using GORM
+type GormAuditStore struct {
+	db *gorm.DB
+}
+
+// NewGormAuditStore creates a new GormAuditStore instance
+func NewGormAuditStore(db *gorm.DB) *GormAuditStore {
+	return &GormAuditStore{db: db}
+}
+
+// WithContext returns a GormAuditStore running all queries with the given context
+func (s *GormAuditStore) WithContext(ctx context.Context) AuditStore {
+	return &GormAuditStore{db: s.db.WithContext(ctx)}
+}
+
+// Initialize creates the audit table
+func (s *GormAuditStore) Initialize() error {
+	return s.db.AutoMigrate(&AuditEntry{})
+}
+
+func (s *GormAuditStore) Record(entry *AuditEntry) error {
+	return s.db.Create(entry).Error
+}
+
+func (s *GormAuditStore) Query(query AuditQuery) ([]AuditEntry, error) {
+	db := s.db
+
+	if query.TargetType != "" {
+		db = db.Where("target_type = ?", query.TargetType)
+	}
+	if query.Target != "" {
+		db = db.Where("target = ?", query.Target)
+	}
+	if query.Actor != "" {
+		db = db.Where("actor = ?", query.Actor)
+	}
+	if !query.Since.IsZero() {
+		db = db.Where("time >= ?", query.Since)
+	}
+	if !query.Until.IsZero() {
+		db = db.Where("time < ?", query.Until)
+	}
+	if query.Limit > 0 {
+		db = db.Limit(query.Limit)
+	}
+
+	var entries []AuditEntry
+	if err := db.Order("time, id").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
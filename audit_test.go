@@ -0,0 +1,240 @@
+package privy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupAuditManager(t *testing.T) *Manager {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+
+	return CreateManager(WithStorage(NewGormStorage(db)), WithAuditStore(NewGormAuditStore(db)))
+}
+
+func TestManager_Audit(t *testing.T) {
+	m := setupAuditManager(t)
+	ctx := WithActor(context.Background(), "alice")
+
+	start := time.Now()
+
+	if _, err := m.CreateResourceContext(ctx, ResourceConfig{
+		Key:     "article",
+		Actions: []Action{DefineAction("read", "Read", ""), DefineAction("update", "Update", "")},
+	}); err != nil {
+		t.Fatalf("failed to create resource: %v", err)
+	}
+	if _, err := m.CreateRoleContext(ctx, "editor", RoleConfig{Permissions: []string{"article.read"}}); err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+	if err := m.AssignPermissionsContext(ctx, "editor", []string{"article.update"}); err != nil {
+		t.Fatalf("failed to assign permissions: %v", err)
+	}
+	// Assigning a permission the role already has does not change it
+	if err := m.AssignPermissionsContext(ctx, "editor", []string{"article.update"}); err != nil {
+		t.Fatalf("failed to assign permissions: %v", err)
+	}
+	if err := m.RemovePermissions("editor", []string{"article.read"}); err != nil {
+		t.Fatalf("failed to remove permissions: %v", err)
+	}
+	if err := m.BindRoleContext(ctx, "user-1", "editor"); err != nil {
+		t.Fatalf("failed to bind role: %v", err)
+	}
+	if err := m.DeleteRoleContext(ctx, "editor"); err != nil {
+		t.Fatalf("failed to delete role: %v", err)
+	}
+	if err := m.DeleteResourceContext(ctx, "article"); err != nil {
+		t.Fatalf("failed to delete resource: %v", err)
+	}
+
+	// Failed changes are not recorded
+	if err := m.AssignPermissionsContext(ctx, "nonexistent", []string{"article.read"}); err != ErrRoleNotFound {
+		t.Fatalf("expected ErrRoleNotFound, got %v", err)
+	}
+
+	entries, err := m.QueryAudit(AuditQuery{TargetType: AuditTargetRole, Target: "editor"})
+	if err != nil {
+		t.Fatalf("failed to query audit log: %v", err)
+	}
+
+	expected := []struct {
+		operation string
+		actor     string
+		before    []string
+		after     []string
+	}{
+		{AuditCreateRole, "alice", nil, []string{"article.read"}},
+		{AuditAssignPermissions, "alice", []string{"article.read"}, []string{"article.read", "article.update"}},
+		{AuditRemovePermissions, "", []string{"article.read", "article.update"}, []string{"article.update"}},
+		{AuditDeleteRole, "alice", []string{"article.update"}, nil},
+	}
+
+	if len(entries) != len(expected) {
+		t.Fatalf("expected %d entries, got %d: %+v", len(expected), len(entries), entries)
+	}
+
+	for i, want := range expected {
+		entry := entries[i]
+		if entry.Operation != want.operation || entry.Actor != want.actor {
+			t.Errorf("entry %d: expected %s by %q, got %s by %q", i, want.operation, want.actor, entry.Operation, entry.Actor)
+		}
+		if entry.Time.Before(start.Add(-time.Second)) {
+			t.Errorf("entry %d: unexpected time %v", i, entry.Time)
+		}
+		assertRoleSnapshot(t, entry.Before, want.before)
+		assertRoleSnapshot(t, entry.After, want.after)
+	}
+
+	entries, err = m.QueryAudit(AuditQuery{TargetType: AuditTargetSubject, Target: "user-1"})
+	if err != nil {
+		t.Fatalf("failed to query audit log: %v", err)
+	}
+	if len(entries) != 1 || string(entries[0].Before) != "[]" || string(entries[0].After) != `["editor"]` {
+		t.Errorf("expected a bind_role entry, got %+v", entries)
+	}
+
+	entries, err = m.QueryAudit(AuditQuery{TargetType: AuditTargetResource, Target: "article"})
+	if err != nil {
+		t.Fatalf("failed to query audit log: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Operation != AuditCreateResource || entries[1].Operation != AuditDeleteResource {
+		t.Fatalf("expected create and delete entries, got %+v", entries)
+	}
+
+	var resource PolicyResource
+	if err := json.Unmarshal(entries[0].After, &resource); err != nil {
+		t.Fatalf("failed to decode snapshot: %v", err)
+	}
+	if resource.Key != "article" || len(resource.Actions) != 2 {
+		t.Errorf("unexpected resource snapshot %s", entries[0].After)
+	}
+	if entries[1].After != nil {
+		t.Errorf("expected no snapshot after deletion, got %s", entries[1].After)
+	}
+}
+
+func TestManager_QueryAudit(t *testing.T) {
+	m := setupAuditManager(t)
+
+	for i, actor := range []string{"alice", "bob", "alice"} {
+		ctx := WithActor(context.Background(), actor)
+		if _, err := m.CreateRoleContext(ctx, fmt.Sprintf("role-%d", i), RoleConfig{}); err != nil {
+			t.Fatalf("failed to create role: %v", err)
+		}
+	}
+
+	all, err := m.QueryAudit(AuditQuery{})
+	if err != nil {
+		t.Fatalf("failed to query audit log: %v", err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(all))
+	}
+
+	tests := []struct {
+		name     string
+		query    AuditQuery
+		expected int
+	}{
+		{"by actor", AuditQuery{Actor: "alice"}, 2},
+		{"by target type", AuditQuery{TargetType: AuditTargetRole}, 3},
+		{"other target type", AuditQuery{TargetType: AuditTargetResource}, 0},
+		{"since", AuditQuery{Since: all[1].Time}, 2},
+		{"until", AuditQuery{Until: all[1].Time}, 1},
+		{"range", AuditQuery{Since: all[0].Time, Until: all[2].Time}, 2},
+		{"limit", AuditQuery{Limit: 1}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := m.QueryAudit(tt.query)
+			if err != nil {
+				t.Fatalf("failed to query audit log: %v", err)
+			}
+			if len(entries) != tt.expected {
+				t.Errorf("expected %d entries, got %d", tt.expected, len(entries))
+			}
+		})
+	}
+}
+
+// failingAuditStore fails to record entries
+type failingAuditStore struct {
+	AuditStore
+}
+
+func (s failingAuditStore) WithContext(ctx context.Context) AuditStore { return s }
+func (s failingAuditStore) Initialize() error                          { return nil }
+func (s failingAuditStore) Record(entry *AuditEntry) error             { return errors.New("disk full") }
+
+func TestManager_AuditErrors(t *testing.T) {
+	if _, err := setupTestManager(t).QueryAudit(AuditQuery{}); err != ErrAuditDisabled {
+		t.Errorf("expected ErrAuditDisabled, got %v", err)
+	}
+
+	m := CreateManager(WithStorage(NewMemoryStorage()), WithAuditStore(failingAuditStore{}))
+
+	role, err := m.CreateRole("editor", RoleConfig{})
+	if !errors.Is(err, ErrAuditFailed) {
+		t.Fatalf("expected ErrAuditFailed, got %v", err)
+	}
+	if role == nil || role.Key != "editor" {
+		t.Errorf("expected the created role with the audit error, got %+v", role)
+	}
+
+	resource, err := m.CreateResource(ResourceConfig{Key: "article"})
+	if !errors.Is(err, ErrAuditFailed) {
+		t.Fatalf("expected ErrAuditFailed, got %v", err)
+	}
+	if resource == nil || resource.Key != "article" {
+		t.Errorf("expected the created resource with the audit error, got %+v", resource)
+	}
+
+	report, err := m.SyncResources([]ResourceConfig{{Key: "user"}}, SyncOptions{})
+	if !errors.Is(err, ErrAuditFailed) {
+		t.Fatalf("expected ErrAuditFailed, got %v", err)
+	}
+	if report == nil || len(report.Changes) != 1 {
+		t.Errorf("expected the sync report with the audit error, got %+v", report)
+	}
+
+	// The change itself has been committed
+	if _, err := m.GetRole("editor"); err != nil {
+		t.Errorf("expected role to exist, got %v", err)
+	}
+}
+
+func assertRoleSnapshot(t *testing.T, snapshot json.RawMessage, permissions []string) {
+	t.Helper()
+
+	if permissions == nil {
+		if snapshot != nil {
+			t.Errorf("expected no snapshot, got %s", snapshot)
+		}
+		return
+	}
+
+	var role PolicyRole
+	if err := json.Unmarshal(snapshot, &role); err != nil {
+		t.Fatalf("failed to decode snapshot %q: %v", snapshot, err)
+	}
+	if len(role.Permissions) != len(permissions) {
+		t.Errorf("expected permissions %v, got %v", permissions, role.Permissions)
+		return
+	}
+	for i := range permissions {
+		if role.Permissions[i] != permissions[i] {
+			t.Errorf("expected permissions %v, got %v", permissions, role.Permissions)
+			return
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package privy
+
+import "context"
+
+// CheckMany checks several permissions against the same roles, loading each role only once.
+// The result maps every required permission to whether any of the roles has it, using the same
+// rules as CheckRolesPermission. Roles that don't exist are skipped.
+// It uses context.Background internally; to specify the context, use CheckManyContext.
+func (m *Manager) CheckMany(roleKeys []string, requiredPermissions []string) (map[string]bool, error) {
+	return m.CheckManyContext(context.Background(), roleKeys, requiredPermissions)
+}
+
+// CheckManyContext is like CheckMany but runs storage operations with the given context
+func (m *Manager) CheckManyContext(ctx context.Context, roleKeys []string, requiredPermissions []string) (map[string]bool, error) {
+	sets, _, err := m.rolePermissionSets(m.storage.WithContext(ctx), roleKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[string]bool, len(requiredPermissions))
+	for _, permission := range requiredPermissions {
+		results[permission] = evaluate(sets, parsePermission(permission)).allowed()
+	}
+
+	return results, nil
+}
+
+// CheckAll checks if the roles have every one of the required permissions.
+// It returns true when no permission is required.
+// It uses context.Background internally; to specify the context, use CheckAllContext.
+func (m *Manager) CheckAll(roleKeys []string, requiredPermissions []string) (bool, error) {
+	return m.CheckAllContext(context.Background(), roleKeys, requiredPermissions)
+}
+
+// CheckAllContext is like CheckAll but runs storage operations with the given context
+func (m *Manager) CheckAllContext(ctx context.Context, roleKeys []string, requiredPermissions []string) (bool, error) {
+	sets, _, err := m.rolePermissionSets(m.storage.WithContext(ctx), roleKeys)
+	if err != nil {
+		return false, err
+	}
+
+	for _, permission := range requiredPermissions {
+		if !evaluate(sets, parsePermission(permission)).allowed() {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// CheckAny checks if the roles have at least one of the required permissions.
+// It returns false when no permission is required.
+// It uses context.Background internally; to specify the context, use CheckAnyContext.
+func (m *Manager) CheckAny(roleKeys []string, requiredPermissions []string) (bool, error) {
+	return m.CheckAnyContext(context.Background(), roleKeys, requiredPermissions)
+}
+
+// CheckAnyContext is like CheckAny but runs storage operations with the given context
+func (m *Manager) CheckAnyContext(ctx context.Context, roleKeys []string, requiredPermissions []string) (bool, error) {
+	sets, _, err := m.rolePermissionSets(m.storage.WithContext(ctx), roleKeys)
+	if err != nil {
+		return false, err
+	}
+
+	for _, permission := range requiredPermissions {
+		if evaluate(sets, parsePermission(permission)).allowed() {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,130 @@
+package privy
+
+import "testing"
+
+func TestManager_CheckMany(t *testing.T) {
+	storage := &countingStorage{MemoryStorage: NewMemoryStorage()}
+	m := CreateManager(WithStorage(storage))
+
+	roles := map[string]RoleConfig{
+		"viewer": {Permissions: []string{"article.read", "comment.read"}},
+		"editor": {Permissions: []string{"article", "!article.delete"}, Parents: []string{"viewer"}},
+		"admin":  {Permissions: []string{"*"}},
+	}
+	for _, key := range []string{"viewer", "editor", "admin"} {
+		if _, err := m.CreateRole(key, roles[key]); err != nil {
+			t.Fatalf("failed to create role: %v", err)
+		}
+	}
+
+	permissions := []string{"article.read", "article.update", "article.delete", "comment.read", "comment.delete"}
+
+	tests := []struct {
+		name     string
+		roleKeys []string
+		expected map[string]bool
+	}{
+		{
+			name:     "single role",
+			roleKeys: []string{"viewer"},
+			expected: map[string]bool{"article.read": true, "comment.read": true},
+		},
+		{
+			name:     "inherited and denied",
+			roleKeys: []string{"editor"},
+			expected: map[string]bool{"article.read": true, "article.update": true, "comment.read": true},
+		},
+		{
+			name:     "deny overrides other roles",
+			roleKeys: []string{"admin", "editor"},
+			expected: map[string]bool{"article.read": true, "article.update": true, "comment.read": true, "comment.delete": true},
+		},
+		{
+			name:     "missing role skipped",
+			roleKeys: []string{"nonexistent", "viewer"},
+			expected: map[string]bool{"article.read": true, "comment.read": true},
+		},
+		{
+			name:     "no roles",
+			roleKeys: nil,
+			expected: map[string]bool{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := m.CheckMany(tt.roleKeys, permissions)
+			if err != nil {
+				t.Fatalf("failed to check permissions: %v", err)
+			}
+
+			if len(results) != len(permissions) {
+				t.Errorf("expected %d results, got %d", len(permissions), len(results))
+			}
+
+			for _, permission := range permissions {
+				if results[permission] != tt.expected[permission] {
+					t.Errorf("expected '%s' = %v, got %v", permission, tt.expected[permission], results[permission])
+				}
+
+				// The batch result must agree with a single check
+				allowed, err := m.CheckRolesPermission(tt.roleKeys, permission)
+				if err != nil {
+					t.Fatalf("failed to check permission: %v", err)
+				}
+				if results[permission] != allowed {
+					t.Errorf("expected '%s' to match CheckRolesPermission (%v), got %v", permission, allowed, results[permission])
+				}
+			}
+		})
+	}
+
+	// Each role, including inherited ones, is loaded once regardless of the number of permissions
+	before := storage.getRole.Load()
+	if _, err := m.CheckMany([]string{"editor", "admin"}, permissions); err != nil {
+		t.Fatalf("failed to check permissions: %v", err)
+	}
+	if lookups := storage.getRole.Load() - before; lookups != 3 {
+		t.Errorf("expected 3 role lookups, got %d", lookups)
+	}
+}
+
+func TestManager_CheckAllAny(t *testing.T) {
+	m := setupTestManager(t)
+
+	if _, err := m.CreateRole("editor", RoleConfig{Permissions: []string{"article", "!article.delete"}}); err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		permissions []string
+		all         bool
+		any         bool
+	}{
+		{"all granted", []string{"article.read", "article.update"}, true, true},
+		{"one denied", []string{"article.read", "article.delete"}, false, true},
+		{"none granted", []string{"article.delete", "user.read"}, false, false},
+		{"empty", nil, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			all, err := m.CheckAll([]string{"editor"}, tt.permissions)
+			if err != nil {
+				t.Fatalf("failed to check all permissions: %v", err)
+			}
+			if all != tt.all {
+				t.Errorf("expected CheckAll %v, got %v", tt.all, all)
+			}
+
+			any, err := m.CheckAny([]string{"editor"}, tt.permissions)
+			if err != nil {
+				t.Fatalf("failed to check any permission: %v", err)
+			}
+			if any != tt.any {
+				t.Errorf("expected CheckAny %v, got %v", tt.any, any)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package privy
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// CacheOptions configures the permission cache enabled by WithCache
+type CacheOptions struct {
+	// TTL bounds how long a role's resolved permissions are reused. It limits staleness after
+	// changes made outside this Manager, e.g. by other processes. Zero means no expiry.
+	TTL time.Duration
+
+	// MaxEntries bounds the number of cached roles; the least recently used role is evicted
+	// first. Zero means no limit.
+	MaxEntries int
+}
+
+// CacheStats reports the effectiveness of the permission cache
+type CacheStats struct {
+	Hits    uint64 `json:"hits"`
+	Misses  uint64 `json:"misses"`
+	Entries int    `json:"entries"`
+}
+
+// WithCache caches roles together with the permissions they inherit, so that permission checks
+// do not query storage for every role. Role changes made through the Manager invalidate the cache.
+func WithCache(opts CacheOptions) ManagerOption {
+	return func(m *Manager) {
+		m.cache = newRoleCache(opts)
+	}
+}
+
+// CacheStats returns the hit and miss counts of the permission cache.
+// It returns zero values when caching is disabled.
+func (m *Manager) CacheStats() CacheStats {
+	if m.cache == nil {
+		return CacheStats{}
+	}
+	return m.cache.stats()
+}
+
+// rolePermissionSet returns the compiled permissions of a role including inherited ones,
+// from the cache if enabled
+func (m *Manager) rolePermissionSet(s Storage, roleKey string) (*PermissionSet, error) {
+	if m.cache != nil {
+		if set, ok := m.cache.get(roleKey); ok {
+			return set, nil
+		}
+	}
+
+	// Remember the generation so that permissions loaded before an invalidation are not stored
+	var generation uint64
+	if m.cache != nil {
+		generation = m.cache.currentGeneration()
+	}
+
+	role, err := s.GetRole(roleKey)
+	if err != nil {
+		return nil, err
+	}
+
+	grants, err := m.resolveRoleGrants(s, role)
+	if err != nil {
+		return nil, err
+	}
+
+	set := compileGrants(grants, m.matchMode)
+	if m.cache != nil {
+		m.cache.put(roleKey, set, generation)
+	}
+
+	return set, nil
+}
+
+// invalidateRoles drops all cached roles after a role has changed. Since roles inherit
+// permissions, a change to one role may affect every role cached.
+func (m *Manager) invalidateRoles() {
+	if m.cache != nil {
+		m.cache.clear()
+	}
+}
+
+// roleCache is an LRU cache of compiled role permissions with an optional TTL
+type roleCache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	entries    map[string]*list.Element
+	lru        *list.List
+	generation uint64
+
+	hits   uint64
+	misses uint64
+}
+
+type roleCacheEntry struct {
+	key     string
+	set     *PermissionSet
+	expires time.Time
+}
+
+func newRoleCache(opts CacheOptions) *roleCache {
+	return &roleCache{
+		ttl:        opts.TTL,
+		maxEntries: opts.MaxEntries,
+		now:        time.Now,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+func (c *roleCache) get(key string) (*PermissionSet, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+
+	entry := elem.Value.(*roleCacheEntry)
+	if c.ttl > 0 && !c.now().Before(entry.expires) {
+		c.lru.Remove(elem)
+		delete(c.entries, key)
+		c.misses++
+		return nil, false
+	}
+
+	c.lru.MoveToFront(elem)
+	c.hits++
+	return entry.set, true
+}
+
+func (c *roleCache) currentGeneration() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.generation
+}
+
+// put stores the permissions of a role unless the cache was invalidated since generation
+func (c *roleCache) put(key string, set *PermissionSet, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+
+	entry := &roleCacheEntry{key: key, set: set}
+	if c.ttl > 0 {
+		entry.expires = c.now().Add(c.ttl)
+	}
+
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = entry
+		c.lru.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.lru.PushFront(entry)
+
+	for c.maxEntries > 0 && c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*roleCacheEntry).key)
+	}
+}
+
+func (c *roleCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	c.entries = make(map[string]*list.Element)
+	c.lru.Init()
+}
+
+func (c *roleCache) stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return CacheStats{Hits: c.hits, Misses: c.misses, Entries: c.lru.Len()}
+}
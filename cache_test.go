@@ -0,0 +1,127 @@
+package privy
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingStorage counts role lookups
+type countingStorage struct {
+	*MemoryStorage
+	getRole atomic.Int64
+}
+
+func (s *countingStorage) WithContext(ctx context.Context) Storage {
+	return s
+}
+
+func (s *countingStorage) GetRole(key string) (*Role, error) {
+	s.getRole.Add(1)
+	return s.MemoryStorage.GetRole(key)
+}
+
+func TestManager_Cache(t *testing.T) {
+	storage := &countingStorage{MemoryStorage: NewMemoryStorage()}
+	m := CreateManager(WithStorage(storage), WithCache(CacheOptions{TTL: time.Minute, MaxEntries: 100}))
+
+	if _, err := m.CreateRole("viewer", RoleConfig{Permissions: []string{"article.read"}}); err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+	if _, err := m.CreateRole("editor", RoleConfig{Parents: []string{"viewer"}}); err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+
+	check := func(roleKey, permission string, expected bool) {
+		t.Helper()
+
+		hasPermission, err := m.CheckRolePermission(roleKey, permission)
+		if err != nil {
+			t.Fatalf("failed to check permission: %v", err)
+		}
+		if hasPermission != expected {
+			t.Errorf("expected %s to have '%s' = %v", roleKey, permission, expected)
+		}
+	}
+
+	check("editor", "article.read", true)
+	lookups := storage.getRole.Load()
+
+	for i := 0; i < 10; i++ {
+		check("editor", "article.read", true)
+	}
+	if storage.getRole.Load() != lookups {
+		t.Errorf("expected cached checks not to query storage, got %d lookups", storage.getRole.Load()-lookups)
+	}
+
+	stats := m.CacheStats()
+	if stats.Hits != 10 || stats.Misses != 1 || stats.Entries != 1 {
+		t.Errorf("expected 10 hits, 1 miss and 1 entry, got %+v", stats)
+	}
+
+	// Changes to an inherited role invalidate the cache
+	if err := m.RemovePermissions("viewer", []string{"article.read"}); err != nil {
+		t.Fatalf("failed to remove permissions: %v", err)
+	}
+	check("editor", "article.read", false)
+
+	if err := m.AssignPermissions("editor", []string{"article.update"}); err != nil {
+		t.Fatalf("failed to assign permissions: %v", err)
+	}
+	check("editor", "article.update", true)
+
+	if err := m.RemoveRoleParents("editor", []string{"viewer"}); err != nil {
+		t.Fatalf("failed to remove role parents: %v", err)
+	}
+	if err := m.AssignPermissions("viewer", []string{"article.read"}); err != nil {
+		t.Fatalf("failed to assign permissions: %v", err)
+	}
+	check("editor", "article.read", false)
+
+	if err := m.DeleteRole("editor"); err != nil {
+		t.Fatalf("failed to delete role: %v", err)
+	}
+	if _, err := m.CheckRolePermission("editor", "article.update"); err != ErrRoleNotFound {
+		t.Errorf("expected ErrRoleNotFound, got %v", err)
+	}
+}
+
+func TestRoleCache_Bounds(t *testing.T) {
+	now := time.Now()
+	c := newRoleCache(CacheOptions{TTL: time.Minute, MaxEntries: 2})
+	c.now = func() time.Time { return now }
+
+	c.put("viewer", CompilePermissionSet([]string{"article.read"}, MatchLegacy), 0)
+	c.put("editor", CompilePermissionSet([]string{"article"}, MatchLegacy), 0)
+
+	// Touch viewer so that editor is the least recently used entry
+	if _, ok := c.get("viewer"); !ok {
+		t.Fatal("expected viewer to be cached")
+	}
+	c.put("admin", CompilePermissionSet([]string{"*"}, MatchLegacy), 0)
+
+	if _, ok := c.get("editor"); ok {
+		t.Error("expected editor to be evicted")
+	}
+	if _, ok := c.get("viewer"); !ok {
+		t.Error("expected viewer to be cached")
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := c.get("admin"); ok {
+		t.Error("expected admin to be expired")
+	}
+
+	// Permissions loaded before an invalidation are not stored
+	generation := c.currentGeneration()
+	c.clear()
+	c.put("viewer", CompilePermissionSet([]string{"article.read"}, MatchLegacy), generation)
+	if _, ok := c.get("viewer"); ok {
+		t.Error("expected stale permissions not to be cached")
+	}
+
+	if stats := c.stats(); stats.Entries != 0 {
+		t.Errorf("expected an empty cache, got %+v", stats)
+	}
+}
@@ -0,0 +1,376 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/weedbox/privy"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// errUsage is returned when a command is called with invalid flags or arguments
+var errUsage = errors.New("invalid usage")
+
+// cli runs commands against a Manager
+type cli struct {
+	m      *privy.Manager
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// openCLI opens the SQLite database at path and creates its tables if needed
+func openCLI(path string, stdin io.Reader, stdout, stderr io.Writer) (*cli, error) {
+	db, err := gorm.Open(sqlite.Open(path), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	storage := privy.NewGormStorage(db)
+	if err := storage.Initialize(); err != nil {
+		return nil, fmt.Errorf("failed to initialize database: %w", err)
+	}
+
+	return &cli{
+		m:      privy.CreateManager(privy.WithStorage(storage)),
+		stdin:  stdin,
+		stdout: stdout,
+		stderr: stderr,
+	}, nil
+}
+
+// dispatch runs the command named by the first arguments
+func (c *cli) dispatch(args []string) error {
+	commands := map[string]func([]string) error{
+		"resource tree": c.resourceTree,
+		"resource add":  c.resourceAdd,
+		"action add":    c.actionAdd,
+		"role list":     c.roleList,
+		"role show":     c.roleShow,
+		"role create":   c.roleCreate,
+		"role grant":    c.roleGrant,
+		"role revoke":   c.roleRevoke,
+		"role delete":   c.roleDelete,
+		"check":         c.check,
+		"export":        c.export,
+		"import":        c.importPolicy,
+	}
+
+	if cmd, ok := commands[args[0]]; ok {
+		return cmd(args[1:])
+	}
+	if len(args) > 1 {
+		if cmd, ok := commands[args[0]+" "+args[1]]; ok {
+			return cmd(args[2:])
+		}
+	}
+
+	fmt.Fprintf(c.stderr, "privy: unknown command %q\n", strings.Join(args, " "))
+	return errUsage
+}
+
+// parse parses the flags of a command and checks the number of remaining arguments
+func (c *cli) parse(fs *flag.FlagSet, args []string, minArgs, maxArgs int) error {
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+
+	if fs.NArg() < minArgs || (maxArgs >= 0 && fs.NArg() > maxArgs) {
+		fmt.Fprintf(c.stderr, "privy: wrong number of arguments for %q\n", fs.Name())
+		return errUsage
+	}
+
+	return nil
+}
+
+func newFlagSet(name string, output io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(output)
+	return fs
+}
+
+// stringsFlag collects the values of a repeated flag
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+func (c *cli) resourceTree(args []string) error {
+	fs := newFlagSet("resource tree", c.stderr)
+	if err := c.parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+
+	resources, err := c.m.ListResources()
+	if err != nil {
+		return err
+	}
+
+	for _, r := range resources {
+		if err := c.printResource(r.Key, 0); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// printResource prints a resource with its actions and, recursively, its sub-resources
+func (c *cli) printResource(path string, depth int) error {
+	r, err := c.m.GetResource(path)
+	if err != nil {
+		return err
+	}
+
+	actions := make([]string, 0, len(r.Actions))
+	for _, a := range r.Actions {
+		actions = append(actions, a.Key)
+	}
+
+	line := strings.Repeat("  ", depth) + r.Key
+	if r.Name != "" {
+		line += fmt.Sprintf(" (%s)", r.Name)
+	}
+	if len(actions) > 0 {
+		line += ": " + strings.Join(actions, ", ")
+	}
+	fmt.Fprintln(c.stdout, line)
+
+	for _, sub := range r.SubResources {
+		if err := c.printResource(path+"."+sub.Key, depth+1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *cli) resourceAdd(args []string) error {
+	fs := newFlagSet("resource add", c.stderr)
+	name := fs.String("name", "", "display name")
+	description := fs.String("description", "", "description")
+	if err := c.parse(fs, args, 1, 1); err != nil {
+		return err
+	}
+
+	path := fs.Arg(0)
+	if _, err := c.m.GetResource(path); err == nil {
+		return privy.ErrResourceExists
+	} else if err != privy.ErrResourceNotFound {
+		return err
+	}
+
+	idx := strings.LastIndex(path, ".")
+	if idx < 0 {
+		_, err := c.m.CreateResource(privy.ResourceConfig{Key: path, Name: *name, Description: *description})
+		return err
+	}
+
+	return c.m.CreateResources(path[:idx], []privy.Resource{
+		{Key: path[idx+1:], Name: *name, Description: *description},
+	})
+}
+
+func (c *cli) actionAdd(args []string) error {
+	fs := newFlagSet("action add", c.stderr)
+	name := fs.String("name", "", "display name")
+	description := fs.String("description", "", "description")
+	if err := c.parse(fs, args, 2, 2); err != nil {
+		return err
+	}
+
+	return c.m.AddActions(fs.Arg(0), []privy.Action{
+		privy.DefineAction(fs.Arg(1), *name, *description),
+	})
+}
+
+func (c *cli) roleList(args []string) error {
+	fs := newFlagSet("role list", c.stderr)
+	if err := c.parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+
+	roles, err := c.m.ListRoles()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tNAME\tPARENTS\tPERMISSIONS")
+	for _, role := range roles {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", role.Key, role.Name,
+			strings.Join(role.Parents, ","), strings.Join(role.Permissions, ","))
+	}
+	return w.Flush()
+}
+
+func (c *cli) roleShow(args []string) error {
+	fs := newFlagSet("role show", c.stderr)
+	if err := c.parse(fs, args, 1, 1); err != nil {
+		return err
+	}
+
+	role, err := c.m.GetRole(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	effective, err := c.m.EffectivePermissions(role.Key, privy.EffectivePermissionsOptions{IncludeGrants: true})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "Key:         %s\n", role.Key)
+	fmt.Fprintf(c.stdout, "Name:        %s\n", role.Name)
+	fmt.Fprintf(c.stdout, "Description: %s\n", role.Description)
+	fmt.Fprintf(c.stdout, "Parents:     %s\n", strings.Join(role.Parents, ", "))
+
+	fmt.Fprintln(c.stdout, "Permissions:")
+	for _, p := range role.Permissions {
+		fmt.Fprintf(c.stdout, "  %s\n", p)
+	}
+
+	fmt.Fprintln(c.stdout, "Effective permissions:")
+	for _, p := range effective {
+		fmt.Fprintf(c.stdout, "  %s (granted by %q of %s)\n", p.Permission, p.Grant, p.Role)
+	}
+
+	return nil
+}
+
+func (c *cli) roleCreate(args []string) error {
+	fs := newFlagSet("role create", c.stderr)
+	name := fs.String("name", "", "display name")
+	description := fs.String("description", "", "description")
+	var parents stringsFlag
+	fs.Var(&parents, "parent", "parent role to inherit from (repeatable)")
+	if err := c.parse(fs, args, 1, -1); err != nil {
+		return err
+	}
+
+	_, err := c.m.CreateRole(fs.Arg(0), privy.RoleConfig{
+		Name:        *name,
+		Description: *description,
+		Permissions: fs.Args()[1:],
+		Parents:     parents,
+	})
+	return err
+}
+
+func (c *cli) roleGrant(args []string) error {
+	fs := newFlagSet("role grant", c.stderr)
+	if err := c.parse(fs, args, 2, -1); err != nil {
+		return err
+	}
+
+	return c.m.AssignPermissions(fs.Arg(0), fs.Args()[1:])
+}
+
+func (c *cli) roleRevoke(args []string) error {
+	fs := newFlagSet("role revoke", c.stderr)
+	if err := c.parse(fs, args, 2, -1); err != nil {
+		return err
+	}
+
+	return c.m.RemovePermissions(fs.Arg(0), fs.Args()[1:])
+}
+
+func (c *cli) roleDelete(args []string) error {
+	fs := newFlagSet("role delete", c.stderr)
+	if err := c.parse(fs, args, 1, 1); err != nil {
+		return err
+	}
+
+	return c.m.DeleteRole(fs.Arg(0))
+}
+
+func (c *cli) check(args []string) error {
+	fs := newFlagSet("check", c.stderr)
+	if err := c.parse(fs, args, 2, 2); err != nil {
+		return err
+	}
+
+	allowed, err := c.m.CheckRolePermission(fs.Arg(0), fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	if !allowed {
+		fmt.Fprintln(c.stdout, "denied")
+		return errDenied
+	}
+
+	fmt.Fprintln(c.stdout, "allowed")
+	return nil
+}
+
+func (c *cli) export(args []string) error {
+	fs := newFlagSet("export", c.stderr)
+	format := fs.String("format", string(privy.FormatYAML), "document format: yaml or json")
+	output := fs.String("o", "", "output file (default stdout)")
+	if err := c.parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+
+	if *output == "" {
+		return c.m.Export(c.stdout, privy.Format(*format))
+	}
+
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+
+	if err := c.m.Export(f, privy.Format(*format)); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (c *cli) importPolicy(args []string) error {
+	fs := newFlagSet("import", c.stderr)
+	format := fs.String("format", string(privy.FormatYAML), "document format: yaml or json")
+	mode := fs.String("mode", "merge", "import mode: merge or replace")
+	if err := c.parse(fs, args, 0, 1); err != nil {
+		return err
+	}
+
+	opts := privy.ImportOptions{Format: privy.Format(*format)}
+	switch *mode {
+	case "merge":
+		opts.Mode = privy.ImportMerge
+	case "replace":
+		opts.Mode = privy.ImportReplace
+	default:
+		fmt.Fprintf(c.stderr, "privy: unknown import mode %q\n", *mode)
+		return errUsage
+	}
+
+	if fs.NArg() == 0 {
+		return c.m.Import(c.stdin, opts)
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return c.m.Import(f, opts)
+}
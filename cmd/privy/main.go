@@ -0,0 +1,93 @@
+// Command privy inspects and changes the RBAC data of a privy SQLite database.
+//
+// Usage:
+//
+//	privy [-db path] <command> [flags] [args]
+//
+// Commands:
+//
+//	resource tree                                  print the resource tree with actions
+//	resource add [-name n] [-description d] <path>  add a resource, e.g. article.comment
+//	action add [-name n] [-description d] <resource> <key>
+//	role list                                      list all roles
+//	role show <role>                               show a role and its effective permissions
+//	role create [-name n] [-description d] [-parent p]... <role> [permission...]
+//	role grant <role> <permission>...              add permissions to a role
+//	role revoke <role> <permission>...             remove permissions from a role
+//	role delete <role>                             delete a role
+//	check <role> <permission>                      print "allowed" or "denied"
+//	export [-format yaml|json] [-o file]           write a policy document
+//	import [-format yaml|json] [-mode merge|replace] [file]
+//
+// Flags of a command must precede its arguments. check exits with status 1 when the
+// permission is denied.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// errDenied is returned by the check command when the permission is denied
+var errDenied = errors.New("permission denied")
+
+func main() {
+	err := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	if err == nil {
+		return
+	}
+
+	if !errors.Is(err, errDenied) && !errors.Is(err, errUsage) {
+		fmt.Fprintf(os.Stderr, "privy: %v\n", err)
+	}
+	if errors.Is(err, errUsage) {
+		os.Exit(2)
+	}
+	os.Exit(1)
+}
+
+// run parses the global flags, opens the database and dispatches to a command
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := newFlagSet("privy", stderr)
+	dbPath := fs.String("db", "privy.db", "path to the SQLite database")
+	fs.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	c, err := openCLI(*dbPath, stdin, stdout, stderr)
+	if err != nil {
+		return err
+	}
+
+	return c.dispatch(fs.Args())
+}
+
+const usage = `Usage: privy [-db path] <command> [flags] [args]
+
+Commands:
+  resource tree
+  resource add [-name n] [-description d] <path>
+  action add [-name n] [-description d] <resource> <key>
+  role list
+  role show <role>
+  role create [-name n] [-description d] [-parent p]... <role> [permission...]
+  role grant <role> <permission>...
+  role revoke <role> <permission>...
+  role delete <role>
+  check <role> <permission>
+  export [-format yaml|json] [-o file]
+  import [-format yaml|json] [-mode merge|replace] [file]
+
+Flags:
+`
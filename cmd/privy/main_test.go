@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	db := filepath.Join(t.TempDir(), "privy.db")
+
+	exec := func(stdin string, args ...string) (string, error) {
+		var stdout, stderr bytes.Buffer
+		err := run(append([]string{"-db", db}, args...), strings.NewReader(stdin), &stdout, &stderr)
+		return stdout.String(), err
+	}
+
+	steps := [][]string{
+		{"resource", "add", "-name", "Article", "article"},
+		{"resource", "add", "article.comment"},
+		{"action", "add", "-name", "Read", "article", "read"},
+		{"action", "add", "article.comment", "create"},
+		{"role", "create", "-name", "Viewer", "viewer", "article.read"},
+		{"role", "create", "-parent", "viewer", "editor"},
+		{"role", "grant", "editor", "article.comment"},
+	}
+	for _, args := range steps {
+		if _, err := exec("", args...); err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+	}
+
+	out, err := exec("", "resource", "tree")
+	if err != nil {
+		t.Fatalf("failed to print resource tree: %v", err)
+	}
+	if expected := "article (Article): read\n  comment: create\n"; out != expected {
+		t.Errorf("expected tree %q, got %q", expected, out)
+	}
+
+	if out, err := exec("", "check", "editor", "article.read"); err != nil || out != "allowed\n" {
+		t.Errorf("expected editor to be allowed 'article.read', got %q, %v", out, err)
+	}
+
+	if _, err := exec("", "role", "revoke", "editor", "article.comment"); err != nil {
+		t.Fatalf("failed to revoke permission: %v", err)
+	}
+	if out, err := exec("", "check", "editor", "article.comment.create"); !errors.Is(err, errDenied) || out != "denied\n" {
+		t.Errorf("expected editor to be denied 'article.comment.create', got %q, %v", out, err)
+	}
+
+	policy, err := exec("", "export")
+	if err != nil {
+		t.Fatalf("failed to export: %v", err)
+	}
+
+	if _, err := exec("", "role", "delete", "editor"); err != nil {
+		t.Fatalf("failed to delete role: %v", err)
+	}
+	if _, err := exec(policy, "import", "-mode", "replace"); err != nil {
+		t.Fatalf("failed to import: %v", err)
+	}
+
+	out, err = exec("", "role", "show", "editor")
+	if err != nil {
+		t.Fatalf("failed to show role: %v", err)
+	}
+	if !strings.Contains(out, "Parents:     viewer") {
+		t.Errorf("expected imported editor to inherit from viewer, got:\n%s", out)
+	}
+
+	if _, err := exec("", "role", "frobnicate"); !errors.Is(err, errUsage) {
+		t.Errorf("expected errUsage, got %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package privy
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// DecisionEvent is a permission check reported to a DecisionLogger.
+// It is suitable for logging as JSON.
+type DecisionEvent struct {
+	// Decision describes the roles, permission and outcome of the check and the grant that matched
+	Decision
+
+	Time time.Time `json:"time"`
+	// Latency is the time the check took
+	Latency time.Duration `json:"latency"`
+}
+
+// DecisionLogger receives permission checks made through CheckRolePermission and
+// CheckRolesPermission, including checks made by Can and the middleware packages. It is called
+// synchronously by the checking goroutine, so it must be safe for concurrent use and should
+// hand events off quickly, e.g. to a buffered channel.
+type DecisionLogger func(ctx context.Context, event DecisionEvent)
+
+// DecisionLogOptions configures WithDecisionLogger
+type DecisionLogOptions struct {
+	// SampleRate is the fraction of checks logged, between 0 and 1. Nil logs every check.
+	SampleRate *float64
+
+	// DenialsOnly logs denied checks only. Sampling applies to the denials.
+	DenialsOnly bool
+}
+
+// WithDecisionLogger reports permission checks to the given logger. The matching grant and
+// deny entry are captured while the check is evaluated, so logging does not access storage.
+func WithDecisionLogger(logger DecisionLogger, opts DecisionLogOptions) ManagerOption {
+	return func(m *Manager) {
+		m.decisionLog = &decisionLog{logger: logger, opts: opts}
+	}
+}
+
+type decisionLog struct {
+	logger DecisionLogger
+	opts   DecisionLogOptions
+}
+
+// sampled reports whether a check with the given outcome should be logged
+func (l *decisionLog) sampled(allowed bool) bool {
+	if l.opts.DenialsOnly && allowed {
+		return false
+	}
+	if l.opts.SampleRate == nil {
+		return true
+	}
+	return rand.Float64() < *l.opts.SampleRate
+}
+
+// logDecision reports a completed permission check to the decision logger, if any
+func (m *Manager) logDecision(ctx context.Context, start time.Time, roleKeys, skipped []string, requiredPermission string, e evaluation) {
+	if m.decisionLog == nil || !m.decisionLog.sampled(e.allowed()) {
+		return
+	}
+
+	m.decisionLog.logger(ctx, DecisionEvent{
+		Decision: m.decision(roleKeys, skipped, requiredPermission, e),
+		Time:     start,
+		Latency:  time.Since(start),
+	})
+}
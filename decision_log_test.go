@@ -0,0 +1,166 @@
+package privy
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+// decisionRecorder collects logged decisions
+type decisionRecorder struct {
+	mu     sync.Mutex
+	events []DecisionEvent
+}
+
+func (r *decisionRecorder) log(ctx context.Context, event DecisionEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, event)
+}
+
+func setupDecisionLogManager(t *testing.T, opts DecisionLogOptions) (*Manager, *decisionRecorder) {
+	recorder := &decisionRecorder{}
+	m := setupTestManager(t, WithDecisionLogger(recorder.log, opts))
+
+	roles := map[string][]string{
+		"viewer": {"article.read"},
+		"editor": {"article", "!article.delete"},
+	}
+	for key, permissions := range roles {
+		if _, err := m.CreateRole(key, RoleConfig{Permissions: permissions}); err != nil {
+			t.Fatalf("failed to create role: %v", err)
+		}
+	}
+
+	return m, recorder
+}
+
+func TestManager_DecisionLogger(t *testing.T) {
+	m, recorder := setupDecisionLogManager(t, DecisionLogOptions{})
+
+	if _, err := m.CheckRolePermission("viewer", "article.read"); err != nil {
+		t.Fatalf("failed to check permission: %v", err)
+	}
+	if _, err := m.CheckRolesPermission([]string{"viewer", "editor", "nonexistent"}, "article.delete"); err != nil {
+		t.Fatalf("failed to check permission: %v", err)
+	}
+	// Failed checks are not logged
+	if _, err := m.CheckRolePermission("nonexistent", "article.read"); err != ErrRoleNotFound {
+		t.Fatalf("expected ErrRoleNotFound, got %v", err)
+	}
+
+	if len(recorder.events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(recorder.events))
+	}
+
+	allowed := recorder.events[0]
+	if !allowed.Allowed || allowed.Permission != "article.read" || allowed.Grant != "article.read" || allowed.Role != "viewer" || allowed.Rule != MatchExact {
+		t.Errorf("unexpected allowed event %+v", allowed)
+	}
+	if allowed.Time.IsZero() || allowed.Latency <= 0 {
+		t.Errorf("expected time and latency, got %v and %v", allowed.Time, allowed.Latency)
+	}
+
+	denied := recorder.events[1]
+	if denied.Allowed || denied.Deny == nil || denied.Deny.Grant != "!article.delete" {
+		t.Errorf("unexpected denied event %+v", denied)
+	}
+	if len(denied.SkippedRoles) != 1 || denied.SkippedRoles[0] != "nonexistent" {
+		t.Errorf("expected skipped role, got %v", denied.SkippedRoles)
+	}
+}
+
+func TestManager_DecisionLoggerCache(t *testing.T) {
+	recorder := &decisionRecorder{}
+	storage := &countingStorage{MemoryStorage: NewMemoryStorage()}
+	m := CreateManager(
+		WithStorage(storage),
+		WithCache(CacheOptions{}),
+		WithDecisionLogger(recorder.log, DecisionLogOptions{}),
+	)
+
+	if _, err := m.CreateRole("viewer", RoleConfig{Permissions: []string{"article.read"}}); err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+	if _, err := m.CreateRole("editor", RoleConfig{Permissions: []string{"!article.delete"}, Parents: []string{"viewer"}}); err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+
+	if _, err := m.CheckRolesPermission([]string{"editor"}, "article"); err != nil {
+		t.Fatalf("failed to check permission: %v", err)
+	}
+	lookups := storage.getRole.Load()
+
+	// Logged checks of cached roles do not query storage
+	for _, permission := range []string{"article.read", "article.delete"} {
+		if _, err := m.CheckRolesPermission([]string{"editor"}, permission); err != nil {
+			t.Fatalf("failed to check permission: %v", err)
+		}
+	}
+	if storage.getRole.Load() != lookups {
+		t.Errorf("expected logged checks not to query storage, got %d lookups", storage.getRole.Load()-lookups)
+	}
+
+	if len(recorder.events) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(recorder.events))
+	}
+
+	// Grants inherited from parent roles are attributed to the requested and the defining role
+	child := recorder.events[0]
+	if !child.Allowed || child.Grant != "article.read" || child.Role != "editor" || child.GrantRole != "viewer" || child.Rule != MatchChild {
+		t.Errorf("unexpected child match event %+v", child)
+	}
+
+	denied := recorder.events[2]
+	if denied.Allowed || denied.Deny == nil || denied.Deny.Grant != "!article.delete" || denied.Deny.GrantRole != "editor" || denied.Grant != "" {
+		t.Errorf("unexpected denied event %+v", denied)
+	}
+}
+
+func TestManager_DecisionLoggerOptions(t *testing.T) {
+	none, half := 0.0, 0.5
+
+	tests := []struct {
+		name    string
+		opts    DecisionLogOptions
+		checks  int
+		min     int
+		max     int
+		allowed bool
+	}{
+		{"all", DecisionLogOptions{}, 100, 200, 200, true},
+		{"denials only", DecisionLogOptions{DenialsOnly: true}, 100, 100, 100, false},
+		{"sampled", DecisionLogOptions{SampleRate: &half}, 1000, 800, 1200, true},
+		{"sampled denials", DecisionLogOptions{SampleRate: &half, DenialsOnly: true}, 1000, 400, 600, false},
+		{"none", DecisionLogOptions{SampleRate: &none}, 100, 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, recorder := setupDecisionLogManager(t, tt.opts)
+
+			for i := 0; i < tt.checks; i++ {
+				if _, err := m.CheckRolePermission("editor", "article.update"); err != nil {
+					t.Fatalf("failed to check permission: %v", err)
+				}
+				if _, err := m.CheckRolePermission("editor", "article.delete"); err != nil {
+					t.Fatalf("failed to check permission: %v", err)
+				}
+			}
+
+			logged := len(recorder.events)
+			if logged < tt.min || logged > tt.max {
+				t.Errorf("expected between %d and %d events, got %d", tt.min, tt.max, logged)
+			}
+
+			sawAllowed := false
+			for _, event := range recorder.events {
+				sawAllowed = sawAllowed || event.Allowed
+			}
+			if sawAllowed != tt.allowed {
+				t.Errorf("expected allowed events %v, got %v", tt.allowed, sawAllowed)
+			}
+		})
+	}
+}
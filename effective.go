@@ -0,0 +1,101 @@
+package privy
+
+import "context"
+
+// EffectivePermission is a concrete "resource.path.action" permission that a role satisfies
+type EffectivePermission struct {
+	Permission string `json:"permission"`
+	// Grant is the permission entry that satisfies Permission, e.g. "article" or "*"
+	Grant string `json:"grant,omitempty"`
+	// Role is the key of the role defining Grant, which may be an inherited parent role
+	Role string `json:"role,omitempty"`
+}
+
+// EffectivePermissionsOptions configures EffectivePermissions
+type EffectivePermissionsOptions struct {
+	// IncludeGrants reports the originating grant and role of each permission
+	IncludeGrants bool
+}
+
+// EffectivePermissions expands the grants of a role, including inherited ones, into the concrete
+// list of registered "resource.path.action" permissions the role satisfies. Permissions revoked
+// by deny entries are left out. Results follow the order of the resource tree.
+// It uses context.Background internally; to specify the context, use EffectivePermissionsContext.
+func (m *Manager) EffectivePermissions(roleKey string, opts EffectivePermissionsOptions) ([]EffectivePermission, error) {
+	return m.EffectivePermissionsContext(context.Background(), roleKey, opts)
+}
+
+// EffectivePermissionsContext is like EffectivePermissions but runs storage operations with the given context
+func (m *Manager) EffectivePermissionsContext(ctx context.Context, roleKey string, opts EffectivePermissionsOptions) ([]EffectivePermission, error) {
+	s := m.storage.WithContext(ctx)
+
+	set, err := m.rolePermissionSet(s, roleKey)
+	if err != nil {
+		return nil, err
+	}
+	sets := []roleSet{{role: roleKey, set: set}}
+
+	permissions, err := m.listActionPermissions(s)
+	if err != nil {
+		return nil, err
+	}
+
+	effective := make([]EffectivePermission, 0)
+	for _, permission := range permissions {
+		e := evaluate(sets, parsePermission(permission))
+		if !e.allowed() {
+			continue
+		}
+
+		ep := EffectivePermission{Permission: permission}
+		if opts.IncludeGrants {
+			ep.Grant = e.grant.permission
+			ep.Role = e.grant.role
+		}
+		effective = append(effective, ep)
+	}
+
+	return effective, nil
+}
+
+// listActionPermissions walks the registered resource tree and lists the permission
+// string of every action, e.g. "article.comment.read"
+func (m *Manager) listActionPermissions(s Storage) ([]string, error) {
+	permissions := make([]string, 0)
+
+	var walk func(parentID *uint, parentPath string) error
+	walk = func(parentID *uint, parentPath string) error {
+		resources, err := s.ListResources(parentID)
+		if err != nil {
+			return err
+		}
+
+		for _, resource := range resources {
+			path := resource.Key
+			if parentPath != "" {
+				path = parentPath + "." + resource.Key
+			}
+
+			actions, err := s.ListActions(resource.ID)
+			if err != nil {
+				return err
+			}
+
+			for _, action := range actions {
+				permissions = append(permissions, BuildPermissionString(path, action.Key))
+			}
+
+			if err := walk(&resource.ID, path); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if err := walk(nil, ""); err != nil {
+		return nil, err
+	}
+
+	return permissions, nil
+}
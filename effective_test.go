@@ -0,0 +1,152 @@
+package privy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func setupEffectiveManager(t *testing.T) *Manager {
+	m := setupTestManager(t)
+
+	_, err := m.CreateResource(ResourceConfig{
+		Key:  "article",
+		Name: "Article",
+		Actions: []Action{
+			DefineAction("read", "Read", "Read article content"),
+			DefineAction("delete", "Delete", "Delete article"),
+		},
+		SubResources: []Resource{
+			{
+				Key:  "comment",
+				Name: "Comment",
+				Actions: []Action{
+					DefineAction("read", "Read Comment", "Read comment content"),
+					DefineAction("delete", "Delete Comment", "Delete comment"),
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create resource: %v", err)
+	}
+
+	_, err = m.CreateResource(ResourceConfig{
+		Key:  "user",
+		Name: "User",
+		Actions: []Action{
+			DefineAction("read", "Read", "Read user profile"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create resource: %v", err)
+	}
+
+	return m
+}
+
+func effectivePermissionStrings(permissions []EffectivePermission) []string {
+	result := make([]string, 0, len(permissions))
+	for _, p := range permissions {
+		result = append(result, p.Permission)
+	}
+	return result
+}
+
+func TestManager_EffectivePermissions(t *testing.T) {
+	m := setupEffectiveManager(t)
+
+	roles := map[string][]string{
+		"admin":     {"*"},
+		"editor":    {"article", "!article.comment.delete"},
+		"reader":    {"*.read"},
+		"moderator": {"article.comment"},
+	}
+	for key, permissions := range roles {
+		if _, err := m.CreateRole(key, RoleConfig{Name: key, Permissions: permissions}); err != nil {
+			t.Fatalf("failed to create role: %v", err)
+		}
+	}
+
+	tests := []struct {
+		roleKey  string
+		expected []string
+	}{
+		{
+			roleKey: "admin",
+			expected: []string{
+				"article.read", "article.delete",
+				"article.comment.read", "article.comment.delete",
+				"user.read",
+			},
+		},
+		{
+			roleKey:  "editor",
+			expected: []string{"article.read", "article.delete", "article.comment.read"},
+		},
+		{
+			roleKey:  "reader",
+			expected: []string{"article.read", "user.read"},
+		},
+		{
+			roleKey:  "moderator",
+			expected: []string{"article.comment.read", "article.comment.delete"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.roleKey, func(t *testing.T) {
+			permissions, err := m.EffectivePermissions(tt.roleKey, EffectivePermissionsOptions{})
+			if err != nil {
+				t.Fatalf("failed to get effective permissions: %v", err)
+			}
+
+			result := effectivePermissionStrings(permissions)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("EffectivePermissions(%q) = %v, want %v", tt.roleKey, result, tt.expected)
+			}
+
+			for _, p := range permissions {
+				if p.Grant != "" || p.Role != "" {
+					t.Errorf("expected no grant details without IncludeGrants, got %+v", p)
+				}
+			}
+		})
+	}
+}
+
+func TestManager_EffectivePermissionsIncludeGrants(t *testing.T) {
+	m := setupEffectiveManager(t)
+
+	if _, err := m.CreateRole("viewer", RoleConfig{Name: "Viewer", Permissions: []string{"article.read"}}); err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+
+	_, err := m.CreateRole("editor", RoleConfig{
+		Name:        "Editor",
+		Permissions: []string{"article.comment"},
+		Parents:     []string{"viewer"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+
+	permissions, err := m.EffectivePermissions("editor", EffectivePermissionsOptions{IncludeGrants: true})
+	if err != nil {
+		t.Fatalf("failed to get effective permissions: %v", err)
+	}
+
+	expected := []EffectivePermission{
+		{Permission: "article.read", Grant: "article.read", Role: "viewer"},
+		{Permission: "article.comment.read", Grant: "article.comment", Role: "editor"},
+		{Permission: "article.comment.delete", Grant: "article.comment", Role: "editor"},
+	}
+
+	if !reflect.DeepEqual(permissions, expected) {
+		t.Errorf("EffectivePermissions() = %+v, want %+v", permissions, expected)
+	}
+
+	_, err = m.EffectivePermissions("nonexistent", EffectivePermissionsOptions{})
+	if err != ErrRoleNotFound {
+		t.Errorf("expected ErrRoleNotFound, got %v", err)
+	}
+}
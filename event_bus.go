@@ -0,0 +1,130 @@
+package privy
+
+import (
+	"context"
+	"sync"
+)
+
+// EventHandler handles an event published by a Manager
+type EventHandler func(ctx context.Context, event Event)
+
+// EventBus is an in-process EventPublisher delivering events to subscribers. Synchronous
+// subscribers run on the goroutine that made the change, before the Manager method returns;
+// asynchronous subscribers run on their own goroutine and receive events in order.
+type EventBus struct {
+	mu          sync.RWMutex
+	subscribers []*subscriber
+}
+
+type subscriber struct {
+	handler EventHandler
+
+	// queue is set for asynchronous subscribers
+	mu     sync.Mutex
+	queue  chan queuedEvent
+	closed bool
+	done   chan struct{}
+}
+
+type queuedEvent struct {
+	ctx   context.Context
+	event Event
+}
+
+// NewEventBus creates a new EventBus
+func NewEventBus() *EventBus {
+	return &EventBus{}
+}
+
+// Subscribe adds a synchronous subscriber and returns a function removing it. The handler
+// delays the change that triggered the event, so it should be fast.
+func (b *EventBus) Subscribe(handler EventHandler) (unsubscribe func()) {
+	sub := &subscriber{handler: handler}
+	b.add(sub)
+
+	return func() {
+		b.remove(sub)
+	}
+}
+
+// SubscribeAsync adds an asynchronous subscriber with a queue of the given size and returns
+// a function removing it. Publishing blocks while the queue is full, so that no event is lost.
+// Unsubscribing waits until the queued events have been handled.
+func (b *EventBus) SubscribeAsync(handler EventHandler, queueSize int) (unsubscribe func()) {
+	sub := &subscriber{
+		handler: handler,
+		queue:   make(chan queuedEvent, queueSize),
+		done:    make(chan struct{}),
+	}
+	b.add(sub)
+
+	go func() {
+		defer close(sub.done)
+		for q := range sub.queue {
+			sub.handler(q.ctx, q.event)
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.remove(sub)
+
+			sub.mu.Lock()
+			sub.closed = true
+			close(sub.queue)
+			sub.mu.Unlock()
+
+			<-sub.done
+		})
+	}
+}
+
+// Publish delivers an event to all subscribers
+func (b *EventBus) Publish(ctx context.Context, event Event) {
+	b.mu.RLock()
+	subscribers := b.subscribers
+	b.mu.RUnlock()
+
+	for _, sub := range subscribers {
+		sub.deliver(ctx, event)
+	}
+}
+
+func (b *EventBus) add(sub *subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// Copy on write, so that Publish can iterate without holding the lock
+	subscribers := make([]*subscriber, 0, len(b.subscribers)+1)
+	b.subscribers = append(append(subscribers, b.subscribers...), sub)
+}
+
+func (b *EventBus) remove(sub *subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	subscribers := make([]*subscriber, 0, len(b.subscribers))
+	for _, s := range b.subscribers {
+		if s != sub {
+			subscribers = append(subscribers, s)
+		}
+	}
+	b.subscribers = subscribers
+}
+
+func (sub *subscriber) deliver(ctx context.Context, event Event) {
+	if sub.queue == nil {
+		sub.handler(ctx, event)
+		return
+	}
+
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+
+	// The subscriber may have been removed after Publish took its list of subscribers
+	if sub.closed {
+		return
+	}
+	sub.queue <- queuedEvent{ctx: ctx, event: event}
+}
@@ -0,0 +1,312 @@
+package privy
+
+import (
+	"context"
+	"slices"
+)
+
+// Event is a change to roles or resources, published once the change has been committed
+type Event interface {
+	// EventType returns the name of the event, e.g. "role_created"
+	EventType() string
+}
+
+// RoleCreated is published when a role has been created
+type RoleCreated struct {
+	Role Role `json:"role"`
+}
+
+// PermissionsAssigned is published when permissions have been added to a role
+type PermissionsAssigned struct {
+	RoleKey     string   `json:"role_key"`
+	Permissions []string `json:"permissions"`
+}
+
+// PermissionsRemoved is published when permissions have been removed from a role
+type PermissionsRemoved struct {
+	RoleKey     string   `json:"role_key"`
+	Permissions []string `json:"permissions"`
+}
+
+// RoleParentsChanged is published when the parents a role inherits permissions from have
+// changed, including when a parent role is deleted. Like a permission change, it changes the effective permissions of the role and of
+// every role inheriting from it.
+type RoleParentsChanged struct {
+	RoleKey string   `json:"role_key"`
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// RoleDeleted is published when a role has been deleted together with its bindings
+type RoleDeleted struct {
+	RoleKey string `json:"role_key"`
+}
+
+// ResourceCreated is published for every created resource, parents before their sub-resources.
+// The actions created with a resource are not published separately.
+type ResourceCreated struct {
+	Path string `json:"path"`
+}
+
+// ActionsAdded is published when actions have been added to an existing resource
+type ActionsAdded struct {
+	ResourcePath string `json:"resource_path"`
+	// Actions are the keys of the added actions
+	Actions []string `json:"actions"`
+}
+
+// ResourceDeleted is published when a resource has been deleted together with its
+// actions and sub-resources
+type ResourceDeleted struct {
+	Path string `json:"path"`
+}
+
+func (RoleCreated) EventType() string         { return "role_created" }
+func (PermissionsAssigned) EventType() string { return "permissions_assigned" }
+func (PermissionsRemoved) EventType() string  { return "permissions_removed" }
+func (RoleParentsChanged) EventType() string  { return "role_parents_changed" }
+func (RoleDeleted) EventType() string         { return "role_deleted" }
+func (ResourceCreated) EventType() string     { return "resource_created" }
+func (ActionsAdded) EventType() string        { return "actions_added" }
+func (ResourceDeleted) EventType() string     { return "resource_deleted" }
+
+// EventPublisher receives the events of a Manager. EventBus is the in-process implementation;
+// implement it to forward events to a message broker.
+type EventPublisher interface {
+	Publish(ctx context.Context, event Event)
+}
+
+// WithEventBus publishes an event for every change made through the Manager once it has been
+// committed. Events are published with the context of the change without its cancellation,
+// so subscribers can read values such as the actor set with WithActor.
+func WithEventBus(bus EventPublisher) ManagerOption {
+	return func(m *Manager) {
+		m.events = bus
+	}
+}
+
+// publish sends committed events to the event bus
+func (m *Manager) publish(ctx context.Context, events []Event) {
+	ctx = context.WithoutCancel(ctx)
+	for _, event := range events {
+		m.events.Publish(ctx, event)
+	}
+}
+
+// eventStorage records the events of the writes made through it during a transaction
+type eventStorage struct {
+	Storage
+	events []Event
+	// created holds the resources created through this storage, whose actions are not
+	// published separately
+	created map[uint]bool
+	// parents holds the parents of updated roles before their first update, in update order,
+	// so that a role updated several times publishes its net change once. Roles losing a
+	// parent that is deleted are tracked as well.
+	parents []roleParents
+}
+
+// roleParents tracks the parents of a role updated in the transaction
+type roleParents struct {
+	id      uint
+	key     string
+	before  []string
+	after   []string
+	deleted bool
+}
+
+func newEventStorage(s Storage) *eventStorage {
+	return &eventStorage{Storage: s, created: make(map[uint]bool)}
+}
+
+// flush returns the recorded events followed by the net parent changes of updated roles
+func (s *eventStorage) flush() []Event {
+	events := s.events
+	for _, rp := range s.parents {
+		if rp.deleted {
+			continue
+		}
+
+		added := missingPermissions(rp.after, rp.before)
+		removed := missingPermissions(rp.before, rp.after)
+		if len(added) > 0 || len(removed) > 0 {
+			events = append(events, RoleParentsChanged{RoleKey: rp.key, Added: added, Removed: removed})
+		}
+	}
+	return events
+}
+
+func (s *eventStorage) CreateResource(resource *Resource) error {
+	if err := s.Storage.CreateResource(resource); err != nil {
+		return err
+	}
+
+	path, err := s.resourcePath(resource)
+	if err != nil {
+		return err
+	}
+
+	s.created[resource.ID] = true
+	s.events = append(s.events, ResourceCreated{Path: path})
+	return nil
+}
+
+func (s *eventStorage) DeleteResource(id uint) error {
+	resource, err := s.Storage.GetResourceByID(id)
+	if err != nil {
+		return err
+	}
+
+	path, err := s.resourcePath(resource)
+	if err != nil {
+		return err
+	}
+
+	if err := s.Storage.DeleteResource(id); err != nil {
+		return err
+	}
+
+	s.events = append(s.events, ResourceDeleted{Path: path})
+	return nil
+}
+
+func (s *eventStorage) CreateActions(resourceID uint, actions []Action) error {
+	if err := s.Storage.CreateActions(resourceID, actions); err != nil {
+		return err
+	}
+
+	if s.created[resourceID] || len(actions) == 0 {
+		return nil
+	}
+
+	resource, err := s.Storage.GetResourceByID(resourceID)
+	if err != nil {
+		return err
+	}
+
+	path, err := s.resourcePath(resource)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(actions))
+	for _, action := range actions {
+		keys = append(keys, action.Key)
+	}
+
+	s.events = append(s.events, ActionsAdded{ResourcePath: path, Actions: keys})
+	return nil
+}
+
+func (s *eventStorage) CreateRole(role *Role) error {
+	if err := s.Storage.CreateRole(role); err != nil {
+		return err
+	}
+
+	s.events = append(s.events, RoleCreated{Role: *role})
+	return nil
+}
+
+func (s *eventStorage) UpdateRole(role *Role) error {
+	previous, err := s.Storage.GetRoleByID(role.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.Storage.UpdateRole(role); err != nil {
+		return err
+	}
+
+	if added := missingPermissions(role.Permissions, previous.Permissions); len(added) > 0 {
+		s.events = append(s.events, PermissionsAssigned{RoleKey: role.Key, Permissions: added})
+	}
+	if removed := missingPermissions(previous.Permissions, role.Permissions); len(removed) > 0 {
+		s.events = append(s.events, PermissionsRemoved{RoleKey: role.Key, Permissions: removed})
+	}
+
+	s.trackParents(previous).after = append([]string(nil), role.Parents...)
+	return nil
+}
+
+func (s *eventStorage) DeleteRole(id uint) error {
+	role, err := s.Storage.GetRoleByID(id)
+	if err != nil {
+		return err
+	}
+
+	// Deleting a role removes the inheritance edges of the roles inheriting from it
+	roles, err := s.Storage.ListRoles()
+	if err != nil {
+		return err
+	}
+
+	if err := s.Storage.DeleteRole(id); err != nil {
+		return err
+	}
+
+	for i := range roles {
+		child := &roles[i]
+		if child.ID == id || !slices.Contains(child.Parents, role.Key) {
+			continue
+		}
+		rp := s.trackParents(child)
+		rp.after = slices.DeleteFunc(slices.Clone(rp.after), func(key string) bool { return key == role.Key })
+	}
+
+	for i := range s.parents {
+		if s.parents[i].id == id {
+			s.parents[i].deleted = true
+		}
+	}
+
+	s.events = append(s.events, RoleDeleted{RoleKey: role.Key})
+	return nil
+}
+
+// trackParents returns the tracked parents of a role, starting from its current parents
+// if the role has not been tracked yet
+func (s *eventStorage) trackParents(role *Role) *roleParents {
+	for i := range s.parents {
+		if s.parents[i].id == role.ID {
+			return &s.parents[i]
+		}
+	}
+
+	s.parents = append(s.parents, roleParents{
+		id:     role.ID,
+		key:    role.Key,
+		before: role.Parents,
+		after:  append([]string(nil), role.Parents...),
+	})
+	return &s.parents[len(s.parents)-1]
+}
+
+// resourcePath builds the path of a resource from its ancestors
+func (s *eventStorage) resourcePath(resource *Resource) (string, error) {
+	path := resource.Key
+	for parentID := resource.ParentID; parentID != nil; {
+		parent, err := s.Storage.GetResourceByID(*parentID)
+		if err != nil {
+			return "", err
+		}
+		path = parent.Key + "." + path
+		parentID = parent.ParentID
+	}
+	return path, nil
+}
+
+// missingPermissions returns the permissions, or role keys, that are not in others
+func missingPermissions(permissions, others []string) []string {
+	exists := make(map[string]bool, len(others))
+	for _, p := range others {
+		exists[p] = true
+	}
+
+	var missing []string
+	for _, p := range permissions {
+		if !exists[p] {
+			missing = append(missing, p)
+		}
+	}
+	return missing
+}
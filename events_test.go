@@ -0,0 +1,287 @@
+package privy
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// eventRecorder collects published events
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (r *eventRecorder) handle(ctx context.Context, event Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, event)
+}
+
+// take returns the recorded events and clears them
+func (r *eventRecorder) take() []Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	events := r.events
+	r.events = nil
+	return events
+}
+
+func TestManager_Events(t *testing.T) {
+	bus := NewEventBus()
+	recorder := &eventRecorder{}
+	bus.Subscribe(recorder.handle)
+
+	m := setupTestManager(t, WithEventBus(bus))
+
+	expect := func(t *testing.T, expected ...Event) {
+		t.Helper()
+
+		events := recorder.take()
+		if len(events) == 0 && len(expected) == 0 {
+			return
+		}
+		if !reflect.DeepEqual(events, expected) {
+			t.Errorf("expected events %+v, got %+v", expected, events)
+		}
+	}
+
+	t.Run("create resource", func(t *testing.T) {
+		_, err := m.CreateResource(ResourceConfig{
+			Key:          "article",
+			Actions:      []Action{DefineAction("read", "Read", "")},
+			SubResources: []Resource{{Key: "comment", Actions: []Action{DefineAction("create", "Create", "")}}},
+		})
+		if err != nil {
+			t.Fatalf("failed to create resource: %v", err)
+		}
+		expect(t, ResourceCreated{Path: "article"}, ResourceCreated{Path: "article.comment"})
+	})
+
+	t.Run("add actions", func(t *testing.T) {
+		if err := m.AddActions("article.comment", []Action{DefineAction("delete", "Delete", "")}); err != nil {
+			t.Fatalf("failed to add actions: %v", err)
+		}
+		expect(t, ActionsAdded{ResourcePath: "article.comment", Actions: []string{"delete"}})
+	})
+
+	t.Run("create resources", func(t *testing.T) {
+		err := m.CreateResources("article", []Resource{
+			{Key: "comment", Actions: []Action{DefineAction("update", "Update", "")}},
+			{Key: "tag"},
+		})
+		if err != nil {
+			t.Fatalf("failed to create resources: %v", err)
+		}
+		expect(t,
+			ActionsAdded{ResourcePath: "article.comment", Actions: []string{"update"}},
+			ResourceCreated{Path: "article.tag"},
+		)
+	})
+
+	t.Run("role lifecycle", func(t *testing.T) {
+		if _, err := m.CreateRole("editor", RoleConfig{Permissions: []string{"article.read"}}); err != nil {
+			t.Fatalf("failed to create role: %v", err)
+		}
+		events := recorder.take()
+		if len(events) != 1 {
+			t.Fatalf("expected 1 event, got %+v", events)
+		}
+		if created, ok := events[0].(RoleCreated); !ok || created.Role.Key != "editor" {
+			t.Errorf("expected RoleCreated for editor, got %+v", events[0])
+		}
+
+		if err := m.AssignPermissions("editor", []string{"article.read", "article.comment"}); err != nil {
+			t.Fatalf("failed to assign permissions: %v", err)
+		}
+		expect(t, PermissionsAssigned{RoleKey: "editor", Permissions: []string{"article.comment"}})
+
+		if err := m.RemovePermissions("editor", []string{"article.read"}); err != nil {
+			t.Fatalf("failed to remove permissions: %v", err)
+		}
+		expect(t, PermissionsRemoved{RoleKey: "editor", Permissions: []string{"article.read"}})
+
+		if err := m.DeleteRole("editor"); err != nil {
+			t.Fatalf("failed to delete role: %v", err)
+		}
+		expect(t, RoleDeleted{RoleKey: "editor"})
+	})
+
+	t.Run("role parents", func(t *testing.T) {
+		for _, key := range []string{"viewer", "author"} {
+			if _, err := m.CreateRole(key, RoleConfig{}); err != nil {
+				t.Fatalf("failed to create role: %v", err)
+			}
+		}
+		if _, err := m.CreateRole("reviewer", RoleConfig{Parents: []string{"viewer"}}); err != nil {
+			t.Fatalf("failed to create role: %v", err)
+		}
+		recorder.take()
+
+		if err := m.AddRoleParents("reviewer", []string{"author"}); err != nil {
+			t.Fatalf("failed to add role parents: %v", err)
+		}
+		expect(t, RoleParentsChanged{RoleKey: "reviewer", Added: []string{"author"}})
+
+		if err := m.RemoveRoleParents("reviewer", []string{"viewer"}); err != nil {
+			t.Fatalf("failed to remove role parents: %v", err)
+		}
+		expect(t, RoleParentsChanged{RoleKey: "reviewer", Removed: []string{"viewer"}})
+
+		// Import rewrites parents in two steps but publishes the net change only
+		doc := `{"version": 1, "roles": [
+			{"key": "viewer"}, {"key": "author"}, {"key": "reviewer", "parents": ["author"]}
+		]}`
+		if err := m.Import(strings.NewReader(doc), ImportOptions{Format: FormatJSON}); err != nil {
+			t.Fatalf("failed to import policy: %v", err)
+		}
+		expect(t)
+
+		doc = strings.Replace(doc, `["author"]`, `["viewer"]`, 1)
+		if err := m.Import(strings.NewReader(doc), ImportOptions{Format: FormatJSON}); err != nil {
+			t.Fatalf("failed to import policy: %v", err)
+		}
+		expect(t, RoleParentsChanged{RoleKey: "reviewer", Added: []string{"viewer"}, Removed: []string{"author"}})
+
+		// Deleting a parent role removes it from the roles inheriting from it
+		if err := m.DeleteRole("viewer"); err != nil {
+			t.Fatalf("failed to delete role: %v", err)
+		}
+		expect(t, RoleDeleted{RoleKey: "viewer"}, RoleParentsChanged{RoleKey: "reviewer", Removed: []string{"viewer"}})
+
+		for _, key := range []string{"reviewer", "author"} {
+			if err := m.DeleteRole(key); err != nil {
+				t.Fatalf("failed to delete role: %v", err)
+			}
+		}
+		recorder.take()
+	})
+
+	t.Run("failed change", func(t *testing.T) {
+		_, err := m.CreateResource(ResourceConfig{Key: "user", SubResources: []Resource{{Key: "a"}, {Key: "a"}}})
+		if err == nil {
+			t.Fatal("expected error for duplicate sub-resources")
+		}
+		if err := m.AssignPermissions("nonexistent", []string{"article.read"}); err != ErrRoleNotFound {
+			t.Fatalf("expected ErrRoleNotFound, got %v", err)
+		}
+		expect(t)
+	})
+
+	t.Run("delete resource", func(t *testing.T) {
+		if err := m.DeleteResource("article.tag"); err != nil {
+			t.Fatalf("failed to delete resource: %v", err)
+		}
+		expect(t, ResourceDeleted{Path: "article.tag"})
+	})
+
+	t.Run("sync", func(t *testing.T) {
+		_, err := m.SyncResources([]ResourceConfig{{
+			Key:     "article",
+			Actions: []Action{DefineAction("read", "Read", ""), DefineAction("update", "Update", "")},
+		}}, SyncOptions{Prune: true})
+		if err != nil {
+			t.Fatalf("failed to sync resources: %v", err)
+		}
+		expect(t,
+			ActionsAdded{ResourcePath: "article", Actions: []string{"update"}},
+			ResourceDeleted{Path: "article.comment"},
+		)
+	})
+
+	t.Run("import", func(t *testing.T) {
+		doc := `{"version": 1, "roles": [{"key": "viewer", "permissions": ["article.read"]}]}`
+		if err := m.Import(strings.NewReader(doc), ImportOptions{Format: FormatJSON}); err != nil {
+			t.Fatalf("failed to import policy: %v", err)
+		}
+		events := recorder.take()
+		if len(events) != 1 || events[0].EventType() != "role_created" {
+			t.Errorf("expected a role_created event, got %+v", events)
+		}
+	})
+}
+
+func TestEventBus(t *testing.T) {
+	bus := NewEventBus()
+
+	syncRecorder := &eventRecorder{}
+	unsubscribeSync := bus.Subscribe(syncRecorder.handle)
+
+	asyncRecorder := &eventRecorder{}
+	unsubscribeAsync := bus.SubscribeAsync(asyncRecorder.handle, 1)
+
+	ctx := WithActor(context.Background(), "alice")
+	var actor string
+	bus.Subscribe(func(ctx context.Context, event Event) {
+		actor = ActorFromContext(ctx)
+	})
+
+	for i := 0; i < 10; i++ {
+		bus.Publish(ctx, RoleDeleted{RoleKey: string(rune('a' + i))})
+	}
+
+	if len(syncRecorder.take()) != 10 {
+		t.Error("expected synchronous subscriber to receive all events")
+	}
+	if actor != "alice" {
+		t.Errorf("expected context to be passed to subscribers, got actor %q", actor)
+	}
+
+	// Unsubscribing waits for queued events to be handled
+	unsubscribeAsync()
+	events := asyncRecorder.take()
+	if len(events) != 10 {
+		t.Fatalf("expected asynchronous subscriber to receive all events, got %d", len(events))
+	}
+	for i, event := range events {
+		if event.(RoleDeleted).RoleKey != string(rune('a'+i)) {
+			t.Errorf("expected events in order, got %+v", events)
+			break
+		}
+	}
+
+	unsubscribeSync()
+	unsubscribeAsync()
+	bus.Publish(ctx, RoleDeleted{RoleKey: "z"})
+	if len(syncRecorder.take()) != 0 || len(asyncRecorder.take()) != 0 {
+		t.Error("expected unsubscribed handlers not to receive events")
+	}
+}
+
+func TestManager_EventsSeeCommittedPermissions(t *testing.T) {
+	bus := NewEventBus()
+	m := setupTestManager(t, WithEventBus(bus), WithCache(CacheOptions{}))
+
+	if _, err := m.CreateRole("editor", RoleConfig{Permissions: []string{"article.read"}}); err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+
+	// Cache the role before the change
+	if hasPermission, err := m.CheckRolePermission("editor", "article.update"); err != nil || hasPermission {
+		t.Fatalf("expected editor without 'article.update', got %v, %v", hasPermission, err)
+	}
+
+	var seen []bool
+	bus.Subscribe(func(ctx context.Context, event Event) {
+		hasPermission, err := m.CheckRolePermission("editor", "article.update")
+		if err != nil {
+			t.Errorf("failed to check permission: %v", err)
+		}
+		seen = append(seen, hasPermission)
+	})
+
+	if err := m.AssignPermissions("editor", []string{"article.update"}); err != nil {
+		t.Fatalf("failed to assign permissions: %v", err)
+	}
+	if err := m.RemovePermissions("editor", []string{"article.update"}); err != nil {
+		t.Fatalf("failed to remove permissions: %v", err)
+	}
+
+	if !reflect.DeepEqual(seen, []bool{true, false}) {
+		t.Errorf("expected subscribers to see the committed permissions [true false], got %v", seen)
+	}
+}
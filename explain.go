@@ -0,0 +1,81 @@
+package privy
+
+import "context"
+
+// Decision explains the outcome of a permission check for a set of roles.
+// It is suitable for logging as JSON.
+type Decision struct {
+	Permission string   `json:"permission"`
+	Roles      []string `json:"roles"`
+	Mode       string   `json:"mode"`
+	Allowed    bool     `json:"allowed"`
+
+	// Role is the requested role holding the matching grant. Grant, GrantRole and Rule
+	// describe that grant; they are also reported when a deny overrides it.
+	Role      string    `json:"role,omitempty"`
+	Grant     string    `json:"grant,omitempty"`
+	GrantRole string    `json:"grant_role,omitempty"`
+	Rule      MatchKind `json:"rule,omitempty"`
+
+	// Deny is the deny entry that revoked the permission, if any
+	Deny *DenyDecision `json:"deny,omitempty"`
+
+	// SkippedRoles lists requested roles that do not exist
+	SkippedRoles []string `json:"skipped_roles,omitempty"`
+}
+
+// DenyDecision describes a deny entry that revoked a permission
+type DenyDecision struct {
+	// Role is the requested role holding the deny entry
+	Role string `json:"role"`
+	// Grant is the deny entry, e.g. "!article.delete"
+	Grant string `json:"grant"`
+	// GrantRole is the key of the role defining the deny entry
+	GrantRole string `json:"grant_role"`
+}
+
+// Explain evaluates the required permission against the given roles like CheckRolesPermission,
+// including the permission cache, and reports how the decision was reached.
+// It uses context.Background internally; to specify the context, use ExplainContext.
+func (m *Manager) Explain(roleKeys []string, requiredPermission string) (*Decision, error) {
+	return m.ExplainContext(context.Background(), roleKeys, requiredPermission)
+}
+
+// ExplainContext is like Explain but runs storage operations with the given context
+func (m *Manager) ExplainContext(ctx context.Context, roleKeys []string, requiredPermission string) (*Decision, error) {
+	sets, skipped, err := m.rolePermissionSets(m.storage.WithContext(ctx), roleKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	decision := m.decision(roleKeys, skipped, requiredPermission, evaluate(sets, parsePermission(requiredPermission)))
+	return &decision, nil
+}
+
+// decision describes an evaluated permission check. Explain and the decision logger share it,
+// so an explanation always reports the grant the check itself matched.
+func (m *Manager) decision(roleKeys, skipped []string, requiredPermission string, e evaluation) Decision {
+	decision := Decision{
+		Permission:   requiredPermission,
+		Roles:        roleKeys,
+		Mode:         m.matchMode.String(),
+		Allowed:      e.allowed(),
+		SkippedRoles: skipped,
+	}
+
+	if e.grant != nil {
+		decision.Role = e.grantRole
+		decision.Grant = e.grant.permission
+		decision.GrantRole = e.grant.role
+		decision.Rule = classifyPermission(requiredPermission, e.grant.permission, m.matchMode)
+	}
+	if e.deny != nil {
+		decision.Deny = &DenyDecision{
+			Role:      e.denyRole,
+			Grant:     e.deny.permission,
+			GrantRole: e.deny.role,
+		}
+	}
+
+	return decision
+}
@@ -0,0 +1,175 @@
+package privy
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestManager_Explain(t *testing.T) {
+	m := setupTestManager(t)
+
+	roles := map[string][]string{
+		"admin":    {"*"},
+		"editor":   {"article", "!article.delete"},
+		"viewer":   {"article.read"},
+		"reader":   {"*.read"},
+		"operator": {"infrastructure.vm.start"},
+	}
+	for key, permissions := range roles {
+		if _, err := m.CreateRole(key, RoleConfig{Name: key, Permissions: permissions}); err != nil {
+			t.Fatalf("failed to create role: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name               string
+		roleKeys           []string
+		requiredPermission string
+		allowed            bool
+		role               string
+		grant              string
+		rule               MatchKind
+		denied             bool
+	}{
+		{"exact", []string{"viewer"}, "article.read", true, "viewer", "article.read", MatchExact, false},
+		{"group", []string{"editor"}, "article.update", true, "editor", "article", MatchGroup, false},
+		{"child", []string{"operator"}, "infrastructure", true, "operator", "infrastructure.vm.start", MatchChild, false},
+		{"wildcard", []string{"viewer", "admin"}, "user.create", true, "admin", "*", MatchWildcard, false},
+		{"pattern", []string{"reader"}, "user.read", true, "reader", "*.read", MatchPattern, false},
+		{"deny", []string{"admin", "editor"}, "article.delete", false, "admin", "*", MatchWildcard, true},
+		{"no match", []string{"viewer"}, "article.delete", false, "", "", MatchNone, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision, err := m.Explain(tt.roleKeys, tt.requiredPermission)
+			if err != nil {
+				t.Fatalf("failed to explain decision: %v", err)
+			}
+
+			if decision.Allowed != tt.allowed {
+				t.Errorf("expected allowed %v, got %v", tt.allowed, decision.Allowed)
+			}
+			if decision.Role != tt.role || decision.Grant != tt.grant || decision.Rule != tt.rule {
+				t.Errorf("expected role %q grant %q rule %q, got role %q grant %q rule %q",
+					tt.role, tt.grant, tt.rule, decision.Role, decision.Grant, decision.Rule)
+			}
+			if (decision.Deny != nil) != tt.denied {
+				t.Errorf("expected denied %v, got %+v", tt.denied, decision.Deny)
+			}
+
+			// Explain must agree with CheckRolesPermission
+			result, err := m.CheckRolesPermission(tt.roleKeys, tt.requiredPermission)
+			if err != nil {
+				t.Fatalf("failed to check roles permission: %v", err)
+			}
+			if result != decision.Allowed {
+				t.Errorf("CheckRolesPermission() = %v, Explain() allowed = %v", result, decision.Allowed)
+			}
+		})
+	}
+}
+
+func TestManager_ExplainDetails(t *testing.T) {
+	m := setupTestManager(t)
+
+	if _, err := m.CreateRole("restricted", RoleConfig{Name: "Restricted", Permissions: []string{"!article.delete"}}); err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+	_, err := m.CreateRole("editor", RoleConfig{
+		Name:        "Editor",
+		Permissions: []string{"article"},
+		Parents:     []string{"restricted"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+
+	decision, err := m.Explain([]string{"nonexistent", "editor"}, "article.delete")
+	if err != nil {
+		t.Fatalf("failed to explain decision: %v", err)
+	}
+
+	if decision.Allowed {
+		t.Error("expected decision to be denied")
+	}
+
+	if len(decision.SkippedRoles) != 1 || decision.SkippedRoles[0] != "nonexistent" {
+		t.Errorf("expected skipped roles [nonexistent], got %v", decision.SkippedRoles)
+	}
+
+	expectedDeny := DenyDecision{Role: "editor", Grant: "!article.delete", GrantRole: "restricted"}
+	if decision.Deny == nil || *decision.Deny != expectedDeny {
+		t.Errorf("expected deny %+v, got %+v", expectedDeny, decision.Deny)
+	}
+
+	data, err := json.Marshal(decision)
+	if err != nil {
+		t.Fatalf("failed to marshal decision: %v", err)
+	}
+
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("failed to unmarshal decision: %v", err)
+	}
+
+	for _, key := range []string{"permission", "allowed", "roles", "mode", "grant", "rule", "deny", "skipped_roles"} {
+		if _, ok := decoded[key]; !ok {
+			t.Errorf("expected JSON field %q in %s", key, data)
+		}
+	}
+}
+
+func TestManager_ExplainMatchesCheck(t *testing.T) {
+	recorder := &decisionRecorder{}
+	m := setupTestManager(t,
+		WithCache(CacheOptions{}),
+		WithDecisionLogger(recorder.log, DecisionLogOptions{}),
+	)
+
+	if _, err := m.CreateResource(ResourceConfig{Key: "article", Actions: []Action{{Key: "read"}}}); err != nil {
+		t.Fatalf("failed to create resource: %v", err)
+	}
+	if _, err := m.CreateRole("admin", RoleConfig{Permissions: []string{"*", "article"}}); err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+
+	if _, err := m.CheckRolePermission("admin", "article.read"); err != nil {
+		t.Fatalf("failed to check permission: %v", err)
+	}
+	if len(recorder.events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(recorder.events))
+	}
+	logged := recorder.events[0].Decision
+
+	decision, err := m.Explain([]string{"admin"}, "article.read")
+	if err != nil {
+		t.Fatalf("failed to explain decision: %v", err)
+	}
+
+	// Explain, the decision logger and the lookups report the grant the check matched
+	if decision.Grant != logged.Grant || decision.Rule != logged.Rule {
+		t.Errorf("Explain() reports %q (%s), check logged %q (%s)", decision.Grant, decision.Rule, logged.Grant, logged.Rule)
+	}
+
+	roles, err := m.RolesWithPermission("article.read")
+	if err != nil {
+		t.Fatalf("failed to list roles: %v", err)
+	}
+	if len(roles) != 1 || roles[0].Grant != logged.Grant {
+		t.Errorf("expected RolesWithPermission to report %q, got %+v", logged.Grant, roles)
+	}
+
+	effective, err := m.EffectivePermissions("admin", EffectivePermissionsOptions{IncludeGrants: true})
+	if err != nil {
+		t.Fatalf("failed to list effective permissions: %v", err)
+	}
+	if len(effective) != 1 || effective[0].Grant != logged.Grant {
+		t.Errorf("expected EffectivePermissions to report %q, got %+v", logged.Grant, effective)
+	}
+
+	// Explain uses the permission cache like the check it explains
+	if stats := m.CacheStats(); stats.Hits == 0 {
+		t.Errorf("expected Explain to hit the cache, got %+v", stats)
+	}
+}
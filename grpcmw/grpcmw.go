@@ -0,0 +1,237 @@
+// Package grpcmw provides gRPC server interceptors that authorize calls with a privy.Manager.
+//
+//	permissions := grpcmw.MethodPermissions(map[string]string{
+//		"/blog.v1.ArticleService/GetArticle":    "article.read",
+//		"/blog.v1.ArticleService/UpdateArticle": "article.update",
+//	})
+//	rolesFrom := grpcmw.RolesFromMetadata("x-roles")
+//
+//	server := grpc.NewServer(
+//		grpc.UnaryInterceptor(grpcmw.UnaryServerInterceptor(m, permissions, rolesFrom)),
+//		grpc.StreamInterceptor(grpcmw.StreamServerInterceptor(m, permissions, rolesFrom)),
+//	)
+//
+// Denied calls fail with codes.PermissionDenied and an errdetails.ErrorInfo detail explaining
+// the decision. Calls to methods without a permission are denied unless listed with
+// WithPublicMethods, and storage errors fail with codes.Internal.
+package grpcmw
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/weedbox/privy"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// ErrorDomain is the domain of the errdetails.ErrorInfo attached to denied calls
+const ErrorDomain = "privy"
+
+// ErrorReason is the reason of the errdetails.ErrorInfo attached to denied calls
+const ErrorReason = "PERMISSION_DENIED"
+
+// PermissionFunc returns the permission required to call a full method name such as
+// "/blog.v1.ArticleService/GetArticle". It returns false when the method has no permission.
+type PermissionFunc func(fullMethod string) (string, bool)
+
+// RolesFunc returns the role keys of the caller from the incoming metadata
+type RolesFunc func(ctx context.Context, md metadata.MD) []string
+
+// MethodPermissions returns a PermissionFunc looking up full method names in a static map
+func MethodPermissions(permissions map[string]string) PermissionFunc {
+	return func(fullMethod string) (string, bool) {
+		permission, ok := permissions[fullMethod]
+		return permission, ok
+	}
+}
+
+// MethodOptionPermissions returns a PermissionFunc reading permissions from the options of
+// method descriptors in protoregistry.GlobalFiles, typically a custom method option:
+//
+//	grpcmw.MethodOptionPermissions(func(opts *descriptorpb.MethodOptions) (string, bool) {
+//		permission, _ := proto.GetExtension(opts, authpb.E_Permission).(string)
+//		return permission, permission != ""
+//	})
+func MethodOptionPermissions(read func(opts *descriptorpb.MethodOptions) (string, bool)) PermissionFunc {
+	return func(fullMethod string) (string, bool) {
+		// "/package.Service/Method" is registered as "package.Service.Method"
+		name := strings.Replace(strings.TrimPrefix(fullMethod, "/"), "/", ".", 1)
+
+		desc, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(name))
+		if err != nil {
+			return "", false
+		}
+
+		method, ok := desc.(protoreflect.MethodDescriptor)
+		if !ok {
+			return "", false
+		}
+
+		opts, _ := method.Options().(*descriptorpb.MethodOptions)
+		if opts == nil {
+			opts = &descriptorpb.MethodOptions{}
+		}
+		return read(opts)
+	}
+}
+
+// RolesFromMetadata returns a RolesFunc reading comma-separated role keys from a metadata key.
+// Only use it when the metadata is set by an authenticating proxy or interceptor.
+func RolesFromMetadata(key string) RolesFunc {
+	return func(ctx context.Context, md metadata.MD) []string {
+		var roles []string
+		for _, value := range md.Get(key) {
+			for _, role := range strings.Split(value, ",") {
+				if role = strings.TrimSpace(role); role != "" {
+					roles = append(roles, role)
+				}
+			}
+		}
+		return roles
+	}
+}
+
+// Option configures the interceptors
+type Option func(*options)
+
+type options struct {
+	public map[string]bool
+}
+
+// WithPublicMethods lets calls to the given full method names, e.g. health checks,
+// through without a permission check
+func WithPublicMethods(fullMethods ...string) Option {
+	return func(o *options) {
+		for _, method := range fullMethods {
+			o.public[method] = true
+		}
+	}
+}
+
+// UnaryServerInterceptor returns an interceptor authorizing unary calls
+func UnaryServerInterceptor(m *privy.Manager, permissionFrom PermissionFunc, rolesFrom RolesFunc, opts ...Option) grpc.UnaryServerInterceptor {
+	a := newAuthorizer(m, permissionFrom, rolesFrom, opts)
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := a.authorize(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns an interceptor authorizing streaming calls when the stream opens
+func StreamServerInterceptor(m *privy.Manager, permissionFrom PermissionFunc, rolesFrom RolesFunc, opts ...Option) grpc.StreamServerInterceptor {
+	a := newAuthorizer(m, permissionFrom, rolesFrom, opts)
+
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := a.authorize(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// authorizer checks calls against the permissions of their methods
+type authorizer struct {
+	m              *privy.Manager
+	permissionFrom PermissionFunc
+	rolesFrom      RolesFunc
+	options
+}
+
+func newAuthorizer(m *privy.Manager, permissionFrom PermissionFunc, rolesFrom RolesFunc, opts []Option) *authorizer {
+	a := &authorizer{
+		m:              m,
+		permissionFrom: permissionFrom,
+		rolesFrom:      rolesFrom,
+		options:        options{public: make(map[string]bool)},
+	}
+
+	for _, opt := range opts {
+		opt(&a.options)
+	}
+
+	return a
+}
+
+// authorize returns a status error unless the caller may call the method
+func (a *authorizer) authorize(ctx context.Context, fullMethod string) error {
+	if a.public[fullMethod] {
+		return nil
+	}
+
+	permission, ok := a.permissionFrom(fullMethod)
+	if !ok {
+		return denied(&privy.Decision{}, fullMethod, "no permission is defined for the method")
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	roles := a.rolesFrom(ctx, md)
+
+	allowed, err := a.m.CheckRolesPermissionContext(ctx, roles, permission)
+	if err != nil {
+		return internalError(err)
+	}
+	if allowed {
+		return nil
+	}
+
+	decision, err := a.m.ExplainContext(ctx, roles, permission)
+	if err != nil {
+		return internalError(err)
+	}
+
+	return denied(decision, fullMethod, "missing permission "+permission)
+}
+
+// denied builds a PermissionDenied status with an ErrorInfo detail describing the decision
+func denied(decision *privy.Decision, fullMethod, message string) error {
+	info := &errdetails.ErrorInfo{
+		Reason: ErrorReason,
+		Domain: ErrorDomain,
+		Metadata: map[string]string{
+			"method": fullMethod,
+		},
+	}
+
+	if decision.Permission != "" {
+		info.Metadata["permission"] = decision.Permission
+		info.Metadata["roles"] = strings.Join(decision.Roles, ",")
+		info.Metadata["mode"] = decision.Mode
+	}
+	if decision.Grant != "" {
+		info.Metadata["grant"] = decision.Grant
+		info.Metadata["grant_role"] = decision.GrantRole
+	}
+	if decision.Deny != nil {
+		info.Metadata["deny"] = decision.Deny.Grant
+		info.Metadata["deny_role"] = decision.Deny.GrantRole
+		message = "permission " + decision.Permission + " is denied by " + decision.Deny.Grant
+	}
+	if len(decision.SkippedRoles) > 0 {
+		info.Metadata["skipped_roles"] = strings.Join(decision.SkippedRoles, ",")
+	}
+
+	st, err := status.New(codes.PermissionDenied, message).WithDetails(info)
+	if err != nil {
+		return status.Error(codes.PermissionDenied, message)
+	}
+	return st.Err()
+}
+
+// internalError converts a failed permission check into a status error
+func internalError(err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+	return status.Error(codes.Internal, "permission check failed")
+}
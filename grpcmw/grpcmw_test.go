@@ -0,0 +1,160 @@
+package grpcmw
+
+import (
+	"context"
+	"testing"
+
+	"github.com/weedbox/privy"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	_ "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+const (
+	getArticle    = "/blog.v1.ArticleService/GetArticle"
+	deleteArticle = "/blog.v1.ArticleService/DeleteArticle"
+	watchArticles = "/blog.v1.ArticleService/WatchArticles"
+)
+
+func setupTestManager(t *testing.T) *privy.Manager {
+	m := privy.CreateManager(privy.WithStorage(privy.NewMemoryStorage()))
+
+	if _, err := m.CreateRole("editor", privy.RoleConfig{Permissions: []string{"article", "!article.delete"}}); err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+
+	return m
+}
+
+func incoming(roles string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-roles", roles))
+}
+
+var permissions = MethodPermissions(map[string]string{
+	getArticle:    "article.read",
+	deleteArticle: "article.delete",
+	watchArticles: "article.read",
+})
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	m := setupTestManager(t)
+	interceptor := UnaryServerInterceptor(m, permissions, RolesFromMetadata("x-roles"),
+		WithPublicMethods("/grpc.health.v1.Health/Check"))
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		return "ok", nil
+	}
+
+	tests := []struct {
+		method string
+		roles  string
+		code   codes.Code
+	}{
+		{getArticle, "editor", codes.OK},
+		{getArticle, "viewer", codes.PermissionDenied},
+		{deleteArticle, "editor", codes.PermissionDenied},
+		{"/blog.v1.ArticleService/Unmapped", "editor", codes.PermissionDenied},
+		{"/grpc.health.v1.Health/Check", "", codes.OK},
+	}
+
+	for _, tt := range tests {
+		_, err := interceptor(incoming(tt.roles), nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+		if code := status.Code(err); code != tt.code {
+			t.Errorf("%s as %q: expected %v, got %v", tt.method, tt.roles, tt.code, err)
+		}
+	}
+}
+
+func TestDeniedDetails(t *testing.T) {
+	m := setupTestManager(t)
+	interceptor := UnaryServerInterceptor(m, permissions, RolesFromMetadata("x-roles"))
+
+	_, err := interceptor(incoming("editor, viewer"), nil, &grpc.UnaryServerInfo{FullMethod: deleteArticle},
+		func(ctx context.Context, req any) (any, error) {
+			t.Error("handler must not be called")
+			return nil, nil
+		})
+
+	st := status.Convert(err)
+	if st.Code() != codes.PermissionDenied {
+		t.Fatalf("expected PermissionDenied, got %v", err)
+	}
+	if len(st.Details()) != 1 {
+		t.Fatalf("expected 1 detail, got %v", st.Details())
+	}
+
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	if !ok {
+		t.Fatalf("expected ErrorInfo detail, got %T", st.Details()[0])
+	}
+
+	expected := map[string]string{
+		"method":        deleteArticle,
+		"permission":    "article.delete",
+		"roles":         "editor,viewer",
+		"mode":          "legacy",
+		"grant":         "article",
+		"grant_role":    "editor",
+		"deny":          "!article.delete",
+		"deny_role":     "editor",
+		"skipped_roles": "viewer",
+	}
+	if info.Reason != ErrorReason || info.Domain != ErrorDomain {
+		t.Errorf("expected reason %q in domain %q, got %q in %q", ErrorReason, ErrorDomain, info.Reason, info.Domain)
+	}
+	for key, value := range expected {
+		if info.Metadata[key] != value {
+			t.Errorf("expected metadata %q to be %q, got %q", key, value, info.Metadata[key])
+		}
+	}
+}
+
+// testStream is a grpc.ServerStream carrying only a context
+type testStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	m := setupTestManager(t)
+	interceptor := StreamServerInterceptor(m, permissions, RolesFromMetadata("x-roles"))
+
+	handler := func(srv any, ss grpc.ServerStream) error {
+		return nil
+	}
+
+	info := &grpc.StreamServerInfo{FullMethod: watchArticles, IsServerStream: true}
+	if err := interceptor(nil, &testStream{ctx: incoming("editor")}, info, handler); err != nil {
+		t.Errorf("expected editor to be allowed, got %v", err)
+	}
+	if err := interceptor(nil, &testStream{ctx: context.Background()}, info, handler); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied without metadata, got %v", err)
+	}
+}
+
+func TestMethodOptionPermissions(t *testing.T) {
+	permissionFrom := MethodOptionPermissions(func(opts *descriptorpb.MethodOptions) (string, bool) {
+		if opts.GetDeprecated() {
+			return "", false
+		}
+		return "health.read", true
+	})
+
+	if permission, ok := permissionFrom("/grpc.health.v1.Health/Check"); !ok || permission != "health.read" {
+		t.Errorf("expected ('health.read', true), got (%q, %v)", permission, ok)
+	}
+	if _, ok := permissionFrom("/grpc.health.v1.Health/Unknown"); ok {
+		t.Error("expected unknown method to have no permission")
+	}
+	if _, ok := permissionFrom("/grpc.health.v1.HealthCheckRequest/Check"); ok {
+		t.Error("expected non-method descriptor to have no permission")
+	}
+}
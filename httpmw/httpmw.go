@@ -0,0 +1,111 @@
+// Package httpmw provides net/http middleware that authorizes requests with a privy.Manager.
+//
+//	mux := http.NewServeMux()
+//	mux.Handle("GET /articles", httpmw.RequirePermission(m, "article.read", rolesFrom)(listArticles))
+//
+// Storage errors are answered with 500 Internal Server Error and denials with 403 Forbidden;
+// both responses can be replaced with options.
+package httpmw
+
+import (
+	"net/http"
+
+	"github.com/weedbox/privy"
+)
+
+// RolesFunc returns the role keys of the caller of a request
+type RolesFunc func(r *http.Request) []string
+
+// PermissionFunc returns the permission required for a request. It returns false when no
+// permission is defined for the request, which is denied.
+type PermissionFunc func(r *http.Request) (string, bool)
+
+// Option configures the middleware
+type Option func(*options)
+
+type options struct {
+	denied  http.Handler
+	onError func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+// WithDeniedBody sets the body and content type of 403 responses
+func WithDeniedBody(contentType string, body []byte) Option {
+	return func(o *options) {
+		o.denied = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", contentType)
+			w.Header().Set("X-Content-Type-Options", "nosniff")
+			w.WriteHeader(http.StatusForbidden)
+			w.Write(body)
+		})
+	}
+}
+
+// WithDeniedHandler sets the handler answering denied requests. It is responsible for
+// writing the status code.
+func WithDeniedHandler(h http.Handler) Option {
+	return func(o *options) {
+		o.denied = h
+	}
+}
+
+// WithErrorHandler sets the function answering requests whose permission check failed
+// with an error. It is responsible for writing the status code.
+func WithErrorHandler(fn func(w http.ResponseWriter, r *http.Request, err error)) Option {
+	return func(o *options) {
+		o.onError = fn
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		denied: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		}),
+		onError: func(w http.ResponseWriter, r *http.Request, err error) {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		},
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// RequirePermission returns middleware that only passes requests whose roles, as returned by
+// rolesFrom, have the given permission
+func RequirePermission(m *privy.Manager, permission string, rolesFrom RolesFunc, opts ...Option) func(http.Handler) http.Handler {
+	return Require(m, func(*http.Request) (string, bool) {
+		return permission, true
+	}, rolesFrom, opts...)
+}
+
+// Require returns middleware that derives the required permission of each request with
+// permissionFrom, e.g. Routes.Permission, and only passes requests whose roles have it
+func Require(m *privy.Manager, permissionFrom PermissionFunc, rolesFrom RolesFunc, opts ...Option) func(http.Handler) http.Handler {
+	o := newOptions(opts)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			permission, ok := permissionFrom(r)
+			if !ok {
+				o.denied.ServeHTTP(w, r)
+				return
+			}
+
+			allowed, err := m.CheckRolesPermissionContext(r.Context(), rolesFrom(r), permission)
+			if err != nil {
+				o.onError(w, r, err)
+				return
+			}
+
+			if !allowed {
+				o.denied.ServeHTTP(w, r)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
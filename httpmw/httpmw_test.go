@@ -0,0 +1,155 @@
+package httpmw
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/weedbox/privy"
+)
+
+// failingStorage fails every role lookup
+type failingStorage struct {
+	*privy.MemoryStorage
+}
+
+func (s failingStorage) WithContext(ctx context.Context) privy.Storage {
+	return s
+}
+
+func (s failingStorage) GetRole(key string) (*privy.Role, error) {
+	return nil, errors.New("database unavailable")
+}
+
+func setupTestManager(t *testing.T) *privy.Manager {
+	m := privy.CreateManager(privy.WithStorage(privy.NewMemoryStorage()))
+
+	if _, err := m.CreateRole("viewer", privy.RoleConfig{Permissions: []string{"article.read", "project.alpha"}}); err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+	if _, err := m.CreateRole("editor", privy.RoleConfig{Permissions: []string{"article"}}); err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+
+	return m
+}
+
+var ok = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
+})
+
+func serve(h http.Handler, method, path, roles string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, nil)
+	if roles != "" {
+		r.Header.Set("X-Roles", roles)
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestRequirePermission(t *testing.T) {
+	m := setupTestManager(t)
+	h := RequirePermission(m, "article.update", RolesFromHeader("X-Roles"))(ok)
+
+	tests := []struct {
+		roles  string
+		status int
+	}{
+		{"editor", http.StatusNoContent},
+		{"viewer, editor", http.StatusNoContent},
+		{"viewer", http.StatusForbidden},
+		{"", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		if w := serve(h, http.MethodPost, "/", tt.roles); w.Code != tt.status {
+			t.Errorf("roles %q: expected status %d, got %d", tt.roles, tt.status, w.Code)
+		}
+	}
+}
+
+func TestRequirePermissionOptions(t *testing.T) {
+	m := setupTestManager(t)
+
+	h := RequirePermission(m, "article.update", RolesFromHeader("X-Roles"),
+		WithDeniedBody("application/json", []byte(`{"error":"forbidden"}`)))(ok)
+
+	w := serve(h, http.MethodPost, "/", "viewer")
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected status 403, got %d", w.Code)
+	}
+	if w.Header().Get("Content-Type") != "application/json" || w.Body.String() != `{"error":"forbidden"}` {
+		t.Errorf("expected custom denied body, got %q (%s)", w.Body.String(), w.Header().Get("Content-Type"))
+	}
+
+	failing := privy.CreateManager(privy.WithStorage(failingStorage{privy.NewMemoryStorage()}))
+	if w := serve(RequirePermission(failing, "article.update", RolesFromHeader("X-Roles"))(ok), http.MethodPost, "/", "editor"); w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", w.Code)
+	}
+
+	var handled error
+	h = RequirePermission(failing, "article.update", RolesFromHeader("X-Roles"),
+		WithErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+			handled = err
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))(ok)
+	if w := serve(h, http.MethodPost, "/", "editor"); w.Code != http.StatusServiceUnavailable || handled == nil {
+		t.Errorf("expected error handler to be called, got status %d", w.Code)
+	}
+}
+
+func TestRoutes(t *testing.T) {
+	routes := (&Routes{}).
+		Handle("GET /articles", "article.read").
+		Handle("/articles/{id}/comments", "article.comment.{action}").
+		Handle("DELETE /projects/{project}/{rest...}", "project.{project}.delete").
+		Handle("/projects/{project}", "project.{project}.{action}").
+		Handle("GET /projects/{project}/files/{file}", "project.{project}.file.{file}.read")
+
+	tests := []struct {
+		method     string
+		path       string
+		permission string
+		ok         bool
+	}{
+		{http.MethodGet, "/articles", "article.read", true},
+		{http.MethodHead, "/articles/", "article.read", true},
+		{http.MethodPost, "/articles", "", false},
+		{http.MethodPost, "/articles/7/comments", "article.comment.create", true},
+		{http.MethodPatch, "/articles/7/comments", "article.comment.update", true},
+		{http.MethodOptions, "/articles/7/comments", "", false},
+		{http.MethodDelete, "/projects/alpha/tasks/3", "project.alpha.delete", true},
+		{http.MethodGet, "/projects/alpha", "project.alpha.read", true},
+		{http.MethodGet, "/projects/a.b", "", false},
+		{http.MethodGet, "/projects/*", "", false},
+		{http.MethodGet, "/projects/alpha/files/notes", "project.alpha.file.notes.read", true},
+		// Values are not expanded again, whatever the order of the path wildcards
+		{http.MethodGet, "/projects/%7Bfile%7D/files/secret", "project.{file}.file.secret.read", true},
+		{http.MethodGet, "/projects/alpha/files/%7Baction%7D", "project.alpha.file.{action}.read", true},
+		{http.MethodGet, "/users", "", false},
+	}
+
+	for _, tt := range tests {
+		permission, ok := routes.Permission(httptest.NewRequest(tt.method, tt.path, nil))
+		if permission != tt.permission || ok != tt.ok {
+			t.Errorf("%s %s: expected (%q, %v), got (%q, %v)", tt.method, tt.path, tt.permission, tt.ok, permission, ok)
+		}
+	}
+
+	m := setupTestManager(t)
+	h := Require(m, routes.Permission, RolesFromHeader("X-Roles"))(ok)
+
+	if w := serve(h, http.MethodGet, "/projects/alpha", "viewer"); w.Code != http.StatusNoContent {
+		t.Errorf("expected status 204, got %d", w.Code)
+	}
+	if w := serve(h, http.MethodGet, "/projects/beta", "viewer"); w.Code != http.StatusForbidden {
+		t.Errorf("expected status 403, got %d", w.Code)
+	}
+	if w := serve(h, http.MethodGet, "/users", "editor"); w.Code != http.StatusForbidden {
+		t.Errorf("expected unmapped route to be denied, got %d", w.Code)
+	}
+}
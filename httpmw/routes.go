@@ -0,0 +1,172 @@
+package httpmw
+
+import (
+	"net/http"
+	"strings"
+)
+
+// ActionPlaceholder in a route permission is replaced with the action of the request method
+const ActionPlaceholder = "{action}"
+
+// MethodActions maps HTTP methods to the actions substituted for ActionPlaceholder
+var MethodActions = map[string]string{
+	http.MethodGet:    "read",
+	http.MethodHead:   "read",
+	http.MethodPost:   "create",
+	http.MethodPut:    "update",
+	http.MethodPatch:  "update",
+	http.MethodDelete: "delete",
+}
+
+// Routes derives the permissions of requests from their method and path.
+// The zero value is ready to use.
+type Routes struct {
+	routes []route
+}
+
+type route struct {
+	method     string
+	segments   []string
+	permission string
+}
+
+// Handle maps a pattern to a permission. Patterns use the syntax of http.ServeMux: an optional
+// method followed by a path template, e.g. "GET /articles/{id}/comments". A {name} segment
+// matches one path segment and a final {name...} segment matches the rest of the path.
+//
+// The permission may refer to path wildcards, e.g. "project.{project}.read", and to
+// ActionPlaceholder, e.g. "article.comment.{action}", which is replaced using MethodActions.
+// Routes are matched in the order they were added.
+func (rt *Routes) Handle(pattern, permission string) *Routes {
+	method, path := "", pattern
+	if i := strings.IndexByte(pattern, ' '); i >= 0 {
+		method, path = pattern[:i], strings.TrimLeft(pattern[i+1:], " ")
+	}
+
+	rt.routes = append(rt.routes, route{
+		method:     method,
+		segments:   splitPath(path),
+		permission: permission,
+	})
+	return rt
+}
+
+// Permission returns the permission of the first route matching the request.
+// It can be passed to Require.
+func (rt *Routes) Permission(r *http.Request) (string, bool) {
+	segments := splitPath(r.URL.Path)
+
+	for _, route := range rt.routes {
+		if route.method != "" && route.method != r.Method &&
+			!(route.method == http.MethodGet && r.Method == http.MethodHead) {
+			continue
+		}
+
+		values, ok := matchSegments(route.segments, segments)
+		if !ok {
+			continue
+		}
+
+		return route.expand(r.Method, values)
+	}
+
+	return "", false
+}
+
+// expand substitutes path values and the method action into the route permission in a single
+// left-to-right pass, so that substituted values are never expanded again
+func (rt route) expand(method string, values map[string]string) (string, bool) {
+	var b strings.Builder
+	rest := rt.permission
+
+	for {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			break
+		}
+		end += start
+
+		placeholder := rest[start : end+1]
+		b.WriteString(rest[:start])
+		rest = rest[end+1:]
+
+		if placeholder == ActionPlaceholder {
+			action, ok := MethodActions[method]
+			if !ok {
+				return "", false
+			}
+			b.WriteString(action)
+			continue
+		}
+
+		value, ok := values[placeholder[1:len(placeholder)-1]]
+		if !ok {
+			b.WriteString(placeholder)
+			continue
+		}
+
+		// Values must not add permission segments or wildcards
+		if value == "" || strings.ContainsAny(value, ".*!") {
+			return "", false
+		}
+		b.WriteString(value)
+	}
+
+	b.WriteString(rest)
+	return b.String(), true
+}
+
+// matchSegments matches path segments against a template and returns the wildcard values
+func matchSegments(template, segments []string) (map[string]string, bool) {
+	values := make(map[string]string)
+
+	for i, t := range template {
+		if name, ok := strings.CutSuffix(t, "...}"); ok && strings.HasPrefix(t, "{") && i == len(template)-1 {
+			values[name[1:]] = strings.Join(segments[min(i, len(segments)):], "/")
+			return values, true
+		}
+
+		if i >= len(segments) {
+			return nil, false
+		}
+
+		if strings.HasPrefix(t, "{") && strings.HasSuffix(t, "}") {
+			values[t[1:len(t)-1]] = segments[i]
+			continue
+		}
+
+		if t != segments[i] {
+			return nil, false
+		}
+	}
+
+	return values, len(template) == len(segments)
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+// RolesFromHeader returns a RolesFunc reading comma-separated role keys from a request header.
+// Only use it behind a proxy that authenticates callers and sets the header.
+func RolesFromHeader(name string) RolesFunc {
+	return func(r *http.Request) []string {
+		var roles []string
+		for _, value := range r.Header.Values(name) {
+			for _, role := range strings.Split(value, ",") {
+				if role = strings.TrimSpace(role); role != "" {
+					roles = append(roles, role)
+				}
+			}
+		}
+		return roles
+	}
+}
@@ -0,0 +1,139 @@
+package privy
+
+import "context"
+
+// RolePermissionMatch describes a role that satisfies a permission
+type RolePermissionMatch struct {
+	Role Role `json:"role"`
+	// Grant is the permission entry that matched, e.g. "article" or "*"
+	Grant string `json:"grant"`
+	// GrantRole is the key of the role defining Grant, which differs from Role.Key
+	// when the grant is inherited from a parent role
+	GrantRole string `json:"grant_role"`
+}
+
+// SubjectPermissionMatch describes a subject that holds a permission through one of its bound roles
+type SubjectPermissionMatch struct {
+	SubjectID string `json:"subject_id"`
+	// Role is the key of the bound role through which the subject holds the permission
+	Role      string `json:"role"`
+	Grant     string `json:"grant"`
+	GrantRole string `json:"grant_role"`
+}
+
+// RolesWithPermission lists all roles that satisfy the required permission, using the same
+// matcher and permission cache as CheckRolePermission, together with the grant that matched in each role.
+// It uses context.Background internally; to specify the context, use RolesWithPermissionContext.
+func (m *Manager) RolesWithPermission(requiredPermission string) ([]RolePermissionMatch, error) {
+	return m.RolesWithPermissionContext(context.Background(), requiredPermission)
+}
+
+// RolesWithPermissionContext is like RolesWithPermission but runs storage operations with the given context
+func (m *Manager) RolesWithPermissionContext(ctx context.Context, requiredPermission string) ([]RolePermissionMatch, error) {
+	s := m.storage.WithContext(ctx)
+
+	roles, err := s.ListRoles()
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]RolePermissionMatch, 0)
+	required := parsePermission(requiredPermission)
+	for _, role := range roles {
+		sets, _, err := m.rolePermissionSets(s, []string{role.Key})
+		if err != nil {
+			return nil, err
+		}
+
+		e := evaluate(sets, required)
+		if !e.allowed() {
+			continue
+		}
+
+		matches = append(matches, RolePermissionMatch{
+			Role:      role,
+			Grant:     e.grant.permission,
+			GrantRole: e.grant.role,
+		})
+	}
+
+	return matches, nil
+}
+
+// SubjectsWithPermission lists all subjects that satisfy the required permission through
+// their bound roles. Like Can, a deny entry in any of a subject's roles excludes the subject.
+// It uses context.Background internally; to specify the context, use SubjectsWithPermissionContext.
+func (m *Manager) SubjectsWithPermission(requiredPermission string) ([]SubjectPermissionMatch, error) {
+	return m.SubjectsWithPermissionContext(context.Background(), requiredPermission)
+}
+
+// SubjectsWithPermissionContext is like SubjectsWithPermission but runs storage operations with the given context
+func (m *Manager) SubjectsWithPermissionContext(ctx context.Context, requiredPermission string) ([]SubjectPermissionMatch, error) {
+	s := m.storage.WithContext(ctx)
+
+	roleMatches, err := m.RolesWithPermissionContext(ctx, requiredPermission)
+	if err != nil {
+		return nil, err
+	}
+
+	// Collect candidate subjects bound to any matching role
+	seen := make(map[string]bool)
+	candidates := make([]string, 0)
+	for _, match := range roleMatches {
+		bindings, err := s.ListRoleBindings(match.Role.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, binding := range bindings {
+			if !seen[binding.SubjectID] {
+				seen[binding.SubjectID] = true
+				candidates = append(candidates, binding.SubjectID)
+			}
+		}
+	}
+
+	matches := make([]SubjectPermissionMatch, 0)
+	for _, subjectID := range candidates {
+		match, ok, err := m.matchSubject(s, subjectID, requiredPermission)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matches = append(matches, match)
+		}
+	}
+
+	return matches, nil
+}
+
+// matchSubject evaluates the required permission against all roles bound to a subject
+func (m *Manager) matchSubject(s Storage, subjectID, requiredPermission string) (SubjectPermissionMatch, bool, error) {
+	roles, err := m.listSubjectRoles(s, subjectID)
+	if err != nil {
+		return SubjectPermissionMatch{}, false, err
+	}
+
+	roleKeys := make([]string, 0, len(roles))
+	for _, role := range roles {
+		roleKeys = append(roleKeys, role.Key)
+	}
+
+	sets, _, err := m.rolePermissionSets(s, roleKeys)
+	if err != nil {
+		return SubjectPermissionMatch{}, false, err
+	}
+
+	// A deny from any bound role overrides all grants
+	e := evaluate(sets, parsePermission(requiredPermission))
+	if !e.allowed() {
+		return SubjectPermissionMatch{}, false, nil
+	}
+
+	return SubjectPermissionMatch{
+		SubjectID: subjectID,
+		Role:      e.grantRole,
+		Grant:     e.grant.permission,
+		GrantRole: e.grant.role,
+	}, true, nil
+}
@@ -0,0 +1,94 @@
+package privy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestManager_RolesWithPermission(t *testing.T) {
+	m := setupTestManager(t)
+
+	roles := []struct {
+		key         string
+		permissions []string
+		parents     []string
+	}{
+		{"admin", []string{"*"}, nil},
+		{"editor", []string{"article", "!article.delete"}, nil},
+		{"publisher", []string{"article.delete"}, nil},
+		{"senior-publisher", nil, []string{"publisher"}},
+		{"viewer", []string{"article.read"}, nil},
+	}
+	for _, r := range roles {
+		_, err := m.CreateRole(r.key, RoleConfig{Name: r.key, Permissions: r.permissions, Parents: r.parents})
+		if err != nil {
+			t.Fatalf("failed to create role: %v", err)
+		}
+	}
+
+	matches, err := m.RolesWithPermission("article.delete")
+	if err != nil {
+		t.Fatalf("failed to find roles with permission: %v", err)
+	}
+
+	type result struct {
+		role, grant, grantRole string
+	}
+	got := make([]result, 0, len(matches))
+	for _, match := range matches {
+		got = append(got, result{match.Role.Key, match.Grant, match.GrantRole})
+	}
+
+	expected := []result{
+		{"admin", "*", "admin"},
+		{"publisher", "article.delete", "publisher"},
+		{"senior-publisher", "article.delete", "publisher"},
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("RolesWithPermission() = %v, want %v", got, expected)
+	}
+}
+
+func TestManager_SubjectsWithPermission(t *testing.T) {
+	m := setupTestManager(t)
+
+	roles := map[string][]string{
+		"publisher":  {"article.delete"},
+		"restricted": {"!article.delete"},
+		"viewer":     {"article.read"},
+	}
+	for key, permissions := range roles {
+		if _, err := m.CreateRole(key, RoleConfig{Name: key, Permissions: permissions}); err != nil {
+			t.Fatalf("failed to create role: %v", err)
+		}
+	}
+
+	bindings := []struct {
+		subjectID, roleKey string
+	}{
+		{"alice", "viewer"},
+		{"alice", "publisher"},
+		{"bob", "publisher"},
+		{"bob", "restricted"},
+		{"carol", "viewer"},
+	}
+	for _, b := range bindings {
+		if err := m.BindRole(b.subjectID, b.roleKey); err != nil {
+			t.Fatalf("failed to bind role: %v", err)
+		}
+	}
+
+	matches, err := m.SubjectsWithPermission("article.delete")
+	if err != nil {
+		t.Fatalf("failed to find subjects with permission: %v", err)
+	}
+
+	expected := []SubjectPermissionMatch{
+		{SubjectID: "alice", Role: "publisher", Grant: "article.delete", GrantRole: "publisher"},
+	}
+
+	if !reflect.DeepEqual(matches, expected) {
+		t.Errorf("SubjectsWithPermission() = %+v, want %+v", matches, expected)
+	}
+}
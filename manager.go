@@ -1,6 +1,7 @@
 package privy
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
@@ -10,11 +11,19 @@ var (
 	ErrInvalidResourcePath = errors.New("invalid resource path")
 	ErrResourceExists      = errors.New("resource already exists")
 	ErrRoleExists          = errors.New("role already exists")
+	ErrRoleCycle           = errors.New("role inheritance cycle")
+	ErrInvalidKey          = errors.New("invalid key")
 )
 
 // Manager manages RBAC resources, actions, and roles
 type Manager struct {
-	storage Storage
+	storage              Storage
+	matchMode            MatchMode
+	permissionValidation bool
+	cache                *roleCache
+	audit                AuditStore
+	decisionLog          *decisionLog
+	events               EventPublisher
 }
 
 // ManagerOption is a function that configures a Manager
@@ -27,6 +36,23 @@ func WithStorage(storage Storage) ManagerOption {
 	}
 }
 
+// WithMatchMode sets how role permissions are matched against required permissions.
+// The default is MatchLegacy.
+func WithMatchMode(mode MatchMode) ManagerOption {
+	return func(m *Manager) {
+		m.matchMode = mode
+	}
+}
+
+// WithPermissionValidation enables validation of role permissions against the registered
+// resources and actions. When enabled, CreateRole and AssignPermissions reject permissions
+// that reference unknown resources or actions with an *UnknownPermissionsError.
+func WithPermissionValidation(enabled bool) ManagerOption {
+	return func(m *Manager) {
+		m.permissionValidation = enabled
+	}
+}
+
 // CreateManager creates a new Manager with the given options
 func CreateManager(opts ...ManagerOption) *Manager {
 	m := &Manager{}
@@ -39,6 +65,9 @@ func CreateManager(opts ...ManagerOption) *Manager {
 	if m.storage != nil {
 		m.storage.Initialize()
 	}
+	if m.audit != nil {
+		m.audit.Initialize()
+	}
 
 	return m
 }
@@ -49,7 +78,7 @@ func parseResourcePath(path string) []string {
 }
 
 // getResourceByPath gets a resource by its path (e.g., "article.comment")
-func (m *Manager) getResourceByPath(path string) (*Resource, error) {
+func (m *Manager) getResourceByPath(s Storage, path string) (*Resource, error) {
 	keys := parseResourcePath(path)
 	if len(keys) == 0 {
 		return nil, ErrInvalidResourcePath
@@ -60,7 +89,7 @@ func (m *Manager) getResourceByPath(path string) (*Resource, error) {
 	var err error
 
 	for _, key := range keys {
-		resource, err = m.storage.GetResource(key, parentID)
+		resource, err = s.GetResource(key, parentID)
 		if err != nil {
 			return nil, err
 		}
@@ -70,145 +99,343 @@ func (m *Manager) getResourceByPath(path string) (*Resource, error) {
 	return resource, nil
 }
 
-// CreateResource creates a new resource with the given configuration
+// CreateResource creates a new resource with the given configuration.
+// Sub-resources are created recursively at any depth, each with its actions, in a single
+// transaction. Keys that cannot be persisted or addressed by a permission string are rejected
+// with ErrInvalidKey, and keys declared twice with ErrDuplicateKey.
+// It uses context.Background internally; to specify the context, use CreateResourceContext.
 func (m *Manager) CreateResource(config ResourceConfig) (*Resource, error) {
-	resource := &Resource{
-		Key:         config.Key,
-		Name:        config.Name,
-		Description: config.Description,
+	return m.CreateResourceContext(context.Background(), config)
+}
+
+// CreateResourceContext is like CreateResource but runs storage operations with the given context
+func (m *Manager) CreateResourceContext(ctx context.Context, config ResourceConfig) (*Resource, error) {
+	return writeResult(ctx, m, m.resourceChange(AuditCreateResource, config.Key), func(s Storage) (*Resource, error) {
+		return m.createResource(s, config)
+	})
+}
+
+// createResource creates a resource with its actions and sub-resources
+func (m *Manager) createResource(s Storage, config ResourceConfig) (*Resource, error) {
+	tree := Resource{
+		Key:          config.Key,
+		Name:         config.Name,
+		Description:  config.Description,
+		Actions:      config.Actions,
+		SubResources: config.SubResources,
+	}
+
+	if err := validateResourceTree(config.Key, tree); err != nil {
+		return nil, err
 	}
 
 	// Check if resource already exists
-	existing, err := m.storage.GetResource(config.Key, nil)
+	existing, err := s.GetResource(config.Key, nil)
 	if err == nil && existing != nil {
 		return nil, ErrResourceExists
 	}
 
-	// Create the resource
-	if err := m.storage.CreateResource(resource); err != nil {
+	resource, err := m.createResourceTree(s, tree, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reload resource with all relations
+	return s.GetResourceByID(resource.ID)
+}
+
+// createResourceTree creates a resource under the given parent together with its actions
+// and, recursively, all of its sub-resources
+func (m *Manager) createResourceTree(s Storage, config Resource, parentID *uint) (*Resource, error) {
+	resource := &Resource{
+		Key:         config.Key,
+		Name:        config.Name,
+		Description: config.Description,
+		ParentID:    parentID,
+	}
+
+	if err := s.CreateResource(resource); err != nil {
 		return nil, err
 	}
 
-	// Create actions
 	if len(config.Actions) > 0 {
-		if err := m.storage.CreateActions(resource.ID, config.Actions); err != nil {
+		if err := s.CreateActions(resource.ID, declaredActions(config.Actions)); err != nil {
 			return nil, err
 		}
 	}
 
-	// Create sub-resources
 	for _, subConfig := range config.SubResources {
-		subResource := &Resource{
-			Key:         subConfig.Key,
-			Name:        subConfig.Name,
-			Description: subConfig.Description,
-			ParentID:    &resource.ID,
+		if _, err := m.createResourceTree(s, subConfig, &resource.ID); err != nil {
+			return nil, err
 		}
+	}
 
-		if err := m.storage.CreateResource(subResource); err != nil {
-			return nil, err
+	return resource, nil
+}
+
+// mergeResources creates sub-resources under a parent. Sub-resources that already exist
+// keep their fields and only get the declared actions and sub-resources added.
+func (m *Manager) mergeResources(s Storage, parentID uint, subResources []Resource) error {
+	for _, subConfig := range subResources {
+		existing, err := s.GetResource(subConfig.Key, &parentID)
+		if err != nil && err != ErrResourceNotFound {
+			return err
 		}
 
-		// Create actions for sub-resource
+		if existing == nil {
+			if _, err := m.createResourceTree(s, subConfig, &parentID); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// Sub-resource exists, just add actions and descend
 		if len(subConfig.Actions) > 0 {
-			if err := m.storage.CreateActions(subResource.ID, subConfig.Actions); err != nil {
-				return nil, err
+			if err := s.CreateActions(existing.ID, declaredActions(subConfig.Actions)); err != nil {
+				return err
 			}
 		}
+
+		if err := m.mergeResources(s, existing.ID, subConfig.SubResources); err != nil {
+			return err
+		}
 	}
 
-	// Reload resource with all relations
-	return m.storage.GetResourceByID(resource.ID)
+	return nil
 }
 
-// AddActions adds actions to an existing resource
-func (m *Manager) AddActions(resourcePath string, actions []Action) error {
-	resource, err := m.getResourceByPath(resourcePath)
-	if err != nil {
-		return err
+// declaredActions copies the declared fields of actions so that creating them neither
+// modifies the caller's configuration nor reuses IDs assigned by an earlier call
+func declaredActions(actions []Action) []Action {
+	copied := make([]Action, 0, len(actions))
+	for _, action := range actions {
+		copied = append(copied, Action{
+			Key:         action.Key,
+			Name:        action.Name,
+			Description: action.Description,
+		})
 	}
-
-	return m.storage.CreateActions(resource.ID, actions)
+	return copied
 }
 
-// CreateResources creates sub-resources under an existing resource
-func (m *Manager) CreateResources(parentPath string, subResources []Resource) error {
-	parent, err := m.getResourceByPath(parentPath)
-	if err != nil {
+// validateResourceTree makes sure every key of a declared resource tree can be persisted and
+// addressed by a permission string, so that nothing is silently lost
+func validateResourceTree(path string, config Resource) error {
+	if err := validateKey(path, config.Key); err != nil {
 		return err
 	}
 
-	for _, subConfig := range subResources {
-		// Check if sub-resource already exists
-		existing, err := m.storage.GetResource(subConfig.Key, &parent.ID)
-		if err == nil && existing != nil {
-			// Sub-resource exists, just add actions
-			if len(subConfig.Actions) > 0 {
-				if err := m.storage.CreateActions(existing.ID, subConfig.Actions); err != nil {
-					return err
-				}
-			}
-			continue
+	if err := validateActions(path, config.Actions); err != nil {
+		return err
+	}
+
+	return validateSubResources(path, config.SubResources)
+}
+
+// validateActions validates the declared actions of the resource at path
+func validateActions(path string, actions []Action) error {
+	keys := make(map[string]bool)
+	for _, action := range actions {
+		if err := validateKey(path+"."+action.Key, action.Key); err != nil {
+			return err
+		}
+		if keys[action.Key] {
+			return fmt.Errorf("%w: action %q declared twice", ErrDuplicateKey, path+"."+action.Key)
 		}
+		keys[action.Key] = true
+	}
 
-		// Create new sub-resource
-		subResource := &Resource{
-			Key:         subConfig.Key,
-			Name:        subConfig.Name,
-			Description: subConfig.Description,
-			ParentID:    &parent.ID,
+	return nil
+}
+
+// validateSubResources validates the declared sub-resources of the resource at path,
+// or top-level resources if path is empty
+func validateSubResources(path string, subResources []Resource) error {
+	keys := make(map[string]bool)
+	for _, sub := range subResources {
+		subPath := sub.Key
+		if path != "" {
+			subPath = path + "." + sub.Key
+		}
+		if keys[sub.Key] {
+			return fmt.Errorf("%w: resource %q declared twice", ErrDuplicateKey, subPath)
 		}
+		keys[sub.Key] = true
 
-		if err := m.storage.CreateResource(subResource); err != nil {
+		if err := validateResourceTree(subPath, sub); err != nil {
 			return err
 		}
+	}
 
-		// Create actions for sub-resource
-		if len(subConfig.Actions) > 0 {
-			if err := m.storage.CreateActions(subResource.ID, subConfig.Actions); err != nil {
-				return err
-			}
-		}
+	return nil
+}
+
+// validateKey checks that a resource or action key is a single permission segment
+func validateKey(path, key string) error {
+	switch {
+	case key == "":
+		return fmt.Errorf("%w %q: empty key", ErrInvalidKey, path)
+	case strings.Contains(key, "."):
+		return fmt.Errorf("%w %q: keys must not contain %q", ErrInvalidKey, path, ".")
+	case strings.Contains(key, WildcardSegment) || strings.Contains(key, DenyPrefix) ||
+		strings.ContainsAny(key, " \t\r\n"):
+		return fmt.Errorf("%w %q: invalid character in key %q", ErrInvalidKey, path, key)
 	}
 
 	return nil
 }
 
-// GetResource gets a resource by its path
+// AddActions adds actions to an existing resource. Action keys are validated like those
+// passed to CreateResource.
+// It uses context.Background internally; to specify the context, use AddActionsContext.
+func (m *Manager) AddActions(resourcePath string, actions []Action) error {
+	return m.AddActionsContext(context.Background(), resourcePath, actions)
+}
+
+// AddActionsContext is like AddActions but runs storage operations with the given context
+func (m *Manager) AddActionsContext(ctx context.Context, resourcePath string, actions []Action) error {
+	if err := validateActions(resourcePath, actions); err != nil {
+		return err
+	}
+
+	return m.write(ctx, m.resourceChange(AuditAddActions, resourcePath), func(s Storage) error {
+		resource, err := m.getResourceByPath(s, resourcePath)
+		if err != nil {
+			return err
+		}
+
+		return s.CreateActions(resource.ID, actions)
+	})
+}
+
+// CreateResources creates sub-resources under an existing resource in a single transaction.
+// Nested sub-resources are created at any depth; for sub-resources that already exist only
+// the declared actions and sub-resources are added.
+// It uses context.Background internally; to specify the context, use CreateResourcesContext.
+func (m *Manager) CreateResources(parentPath string, subResources []Resource) error {
+	return m.CreateResourcesContext(context.Background(), parentPath, subResources)
+}
+
+// CreateResourcesContext is like CreateResources but runs storage operations with the given context
+func (m *Manager) CreateResourcesContext(ctx context.Context, parentPath string, subResources []Resource) error {
+	return m.write(ctx, m.resourceChange(AuditCreateResources, parentPath), func(s Storage) error {
+		return m.createResources(s, parentPath, subResources)
+	})
+}
+
+// createResources creates sub-resources under an existing resource
+func (m *Manager) createResources(s Storage, parentPath string, subResources []Resource) error {
+	parent, err := m.getResourceByPath(s, parentPath)
+	if err != nil {
+		return err
+	}
+
+	if err := validateSubResources(parentPath, subResources); err != nil {
+		return err
+	}
+
+	return m.mergeResources(s, parent.ID, subResources)
+}
+
+// GetResource gets a resource by its path.
+// It uses context.Background internally; to specify the context, use GetResourceContext.
 func (m *Manager) GetResource(path string) (*Resource, error) {
-	return m.getResourceByPath(path)
+	return m.GetResourceContext(context.Background(), path)
 }
 
-// ListResources lists all top-level resources
+// GetResourceContext is like GetResource but runs storage operations with the given context
+func (m *Manager) GetResourceContext(ctx context.Context, path string) (*Resource, error) {
+	s := m.storage.WithContext(ctx)
+
+	return m.getResourceByPath(s, path)
+}
+
+// ListResources lists all top-level resources.
+// It uses context.Background internally; to specify the context, use ListResourcesContext.
 func (m *Manager) ListResources() ([]Resource, error) {
-	return m.storage.ListResources(nil)
+	return m.ListResourcesContext(context.Background())
 }
 
-// CreateRole creates a new role with the given configuration
+// ListResourcesContext is like ListResources but runs storage operations with the given context
+func (m *Manager) ListResourcesContext(ctx context.Context) ([]Resource, error) {
+	s := m.storage.WithContext(ctx)
+
+	return s.ListResources(nil)
+}
+
+// CreateRole creates a new role with the given configuration.
+// It uses context.Background internally; to specify the context, use CreateRoleContext.
 func (m *Manager) CreateRole(key string, config RoleConfig) (*Role, error) {
+	return m.CreateRoleContext(context.Background(), key, config)
+}
+
+// CreateRoleContext is like CreateRole but runs storage operations with the given context
+func (m *Manager) CreateRoleContext(ctx context.Context, key string, config RoleConfig) (*Role, error) {
+	return writeResult(ctx, m, roleChange(AuditCreateRole, key), func(s Storage) (*Role, error) {
+		return m.createRole(s, key, config)
+	})
+}
+
+// createRole creates a role after validating its permissions and parents
+func (m *Manager) createRole(s Storage, key string, config RoleConfig) (*Role, error) {
 	// Check if role already exists
-	existing, err := m.storage.GetRole(key)
+	existing, err := s.GetRole(key)
 	if err == nil && existing != nil {
 		return nil, ErrRoleExists
 	}
 
+	if err := validatePermissions(config.Permissions); err != nil {
+		return nil, err
+	}
+
+	if err := m.validateRegisteredPermissions(s, config.Permissions); err != nil {
+		return nil, err
+	}
+
+	// Make sure parent roles exist and do not lead back to this role
+	if err := m.checkRoleCycle(s, key, config.Parents); err != nil {
+		return nil, err
+	}
+
 	role := &Role{
 		Key:         key,
 		Name:        config.Name,
 		Description: config.Description,
 		Permissions: config.Permissions,
+		Parents:     config.Parents,
 	}
 
-	if err := m.storage.CreateRole(role); err != nil {
+	if err := s.CreateRole(role); err != nil {
 		return nil, err
 	}
 
 	return role, nil
 }
 
-// AssignPermissions adds permissions to an existing role
+// AssignPermissions adds permissions to an existing role.
+// It uses context.Background internally; to specify the context, use AssignPermissionsContext.
 func (m *Manager) AssignPermissions(roleKey string, permissions []string) error {
-	role, err := m.storage.GetRole(roleKey)
+	return m.AssignPermissionsContext(context.Background(), roleKey, permissions)
+}
+
+// AssignPermissionsContext is like AssignPermissions but runs storage operations with the given context
+func (m *Manager) AssignPermissionsContext(ctx context.Context, roleKey string, permissions []string) error {
+	return m.write(ctx, roleChange(AuditAssignPermissions, roleKey), func(s Storage) error {
+		return m.assignPermissions(s, roleKey, permissions)
+	})
+}
+
+// assignPermissions adds permissions to an existing role
+func (m *Manager) assignPermissions(s Storage, roleKey string, permissions []string) error {
+	if err := validatePermissions(permissions); err != nil {
+		return err
+	}
+
+	if err := m.validateRegisteredPermissions(s, permissions); err != nil {
+		return err
+	}
+
+	role, err := s.GetRole(roleKey)
 	if err != nil {
 		return err
 	}
@@ -226,12 +453,25 @@ func (m *Manager) AssignPermissions(roleKey string, permissions []string) error
 		}
 	}
 
-	return m.storage.UpdateRole(role)
+	return s.UpdateRole(role)
 }
 
-// RemovePermissions removes permissions from an existing role
+// RemovePermissions removes permissions from an existing role.
+// It uses context.Background internally; to specify the context, use RemovePermissionsContext.
 func (m *Manager) RemovePermissions(roleKey string, permissions []string) error {
-	role, err := m.storage.GetRole(roleKey)
+	return m.RemovePermissionsContext(context.Background(), roleKey, permissions)
+}
+
+// RemovePermissionsContext is like RemovePermissions but runs storage operations with the given context
+func (m *Manager) RemovePermissionsContext(ctx context.Context, roleKey string, permissions []string) error {
+	return m.write(ctx, roleChange(AuditRemovePermissions, roleKey), func(s Storage) error {
+		return m.removePermissions(s, roleKey, permissions)
+	})
+}
+
+// removePermissions removes permissions from an existing role
+func (m *Manager) removePermissions(s Storage, roleKey string, permissions []string) error {
+	role, err := s.GetRole(roleKey)
 	if err != nil {
 		return err
 	}
@@ -251,37 +491,174 @@ func (m *Manager) RemovePermissions(roleKey string, permissions []string) error
 	}
 
 	role.Permissions = newPermissions
-	return m.storage.UpdateRole(role)
+	return s.UpdateRole(role)
 }
 
-// GetRole gets a role by its key
-func (m *Manager) GetRole(key string) (*Role, error) {
-	return m.storage.GetRole(key)
+// AddRoleParents adds parent roles to an existing role so that it inherits their permissions.
+// It uses context.Background internally; to specify the context, use AddRoleParentsContext.
+func (m *Manager) AddRoleParents(roleKey string, parentKeys []string) error {
+	return m.AddRoleParentsContext(context.Background(), roleKey, parentKeys)
 }
 
-// ListRoles lists all roles
-func (m *Manager) ListRoles() ([]Role, error) {
-	return m.storage.ListRoles()
+// AddRoleParentsContext is like AddRoleParents but runs storage operations with the given context
+func (m *Manager) AddRoleParentsContext(ctx context.Context, roleKey string, parentKeys []string) error {
+	return m.write(ctx, roleChange(AuditAddRoleParents, roleKey), func(s Storage) error {
+		return m.addRoleParents(s, roleKey, parentKeys)
+	})
 }
 
-// DeleteRole deletes a role by its key
-func (m *Manager) DeleteRole(key string) error {
-	role, err := m.storage.GetRole(key)
+// addRoleParents adds parent roles to an existing role
+func (m *Manager) addRoleParents(s Storage, roleKey string, parentKeys []string) error {
+	role, err := s.GetRole(roleKey)
 	if err != nil {
 		return err
 	}
 
-	return m.storage.DeleteRole(role.ID)
+	if err := m.checkRoleCycle(s, roleKey, parentKeys); err != nil {
+		return err
+	}
+
+	// Add parents (avoiding duplicates)
+	parentMap := make(map[string]bool)
+	for _, p := range role.Parents {
+		parentMap[p] = true
+	}
+
+	for _, p := range parentKeys {
+		if !parentMap[p] {
+			role.Parents = append(role.Parents, p)
+			parentMap[p] = true
+		}
+	}
+
+	return s.UpdateRole(role)
 }
 
-// DeleteResource deletes a resource by its path
-func (m *Manager) DeleteResource(path string) error {
-	resource, err := m.getResourceByPath(path)
+// RemoveRoleParents removes parent roles from an existing role.
+// It uses context.Background internally; to specify the context, use RemoveRoleParentsContext.
+func (m *Manager) RemoveRoleParents(roleKey string, parentKeys []string) error {
+	return m.RemoveRoleParentsContext(context.Background(), roleKey, parentKeys)
+}
+
+// RemoveRoleParentsContext is like RemoveRoleParents but runs storage operations with the given context
+func (m *Manager) RemoveRoleParentsContext(ctx context.Context, roleKey string, parentKeys []string) error {
+	return m.write(ctx, roleChange(AuditRemoveRoleParents, roleKey), func(s Storage) error {
+		return m.removeRoleParents(s, roleKey, parentKeys)
+	})
+}
+
+// removeRoleParents removes parent roles from an existing role
+func (m *Manager) removeRoleParents(s Storage, roleKey string, parentKeys []string) error {
+	role, err := s.GetRole(roleKey)
 	if err != nil {
 		return err
 	}
 
-	return m.storage.DeleteResource(resource.ID)
+	// Create a map for quick lookup
+	toRemove := make(map[string]bool)
+	for _, p := range parentKeys {
+		toRemove[p] = true
+	}
+
+	// Filter out parents to remove
+	newParents := make([]string, 0)
+	for _, p := range role.Parents {
+		if !toRemove[p] {
+			newParents = append(newParents, p)
+		}
+	}
+
+	role.Parents = newParents
+	return s.UpdateRole(role)
+}
+
+// checkRoleCycle makes sure all parent roles exist and none of them inherits from roleKey
+func (m *Manager) checkRoleCycle(s Storage, roleKey string, parentKeys []string) error {
+	visited := make(map[string]bool)
+	pending := append([]string(nil), parentKeys...)
+
+	for len(pending) > 0 {
+		key := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		if key == roleKey {
+			return ErrRoleCycle
+		}
+		if visited[key] {
+			continue
+		}
+		visited[key] = true
+
+		parent, err := s.GetRole(key)
+		if err != nil {
+			return err
+		}
+		pending = append(pending, parent.Parents...)
+	}
+
+	return nil
+}
+
+// GetRole gets a role by its key.
+// It uses context.Background internally; to specify the context, use GetRoleContext.
+func (m *Manager) GetRole(key string) (*Role, error) {
+	return m.GetRoleContext(context.Background(), key)
+}
+
+// GetRoleContext is like GetRole but runs storage operations with the given context
+func (m *Manager) GetRoleContext(ctx context.Context, key string) (*Role, error) {
+	s := m.storage.WithContext(ctx)
+
+	return s.GetRole(key)
+}
+
+// ListRoles lists all roles.
+// It uses context.Background internally; to specify the context, use ListRolesContext.
+func (m *Manager) ListRoles() ([]Role, error) {
+	return m.ListRolesContext(context.Background())
+}
+
+// ListRolesContext is like ListRoles but runs storage operations with the given context
+func (m *Manager) ListRolesContext(ctx context.Context) ([]Role, error) {
+	s := m.storage.WithContext(ctx)
+
+	return s.ListRoles()
+}
+
+// DeleteRole deletes a role by its key.
+// It uses context.Background internally; to specify the context, use DeleteRoleContext.
+func (m *Manager) DeleteRole(key string) error {
+	return m.DeleteRoleContext(context.Background(), key)
+}
+
+// DeleteRoleContext is like DeleteRole but runs storage operations with the given context
+func (m *Manager) DeleteRoleContext(ctx context.Context, key string) error {
+	return m.write(ctx, roleChange(AuditDeleteRole, key), func(s Storage) error {
+		role, err := s.GetRole(key)
+		if err != nil {
+			return err
+		}
+
+		return s.DeleteRole(role.ID)
+	})
+}
+
+// DeleteResource deletes a resource by its path.
+// It uses context.Background internally; to specify the context, use DeleteResourceContext.
+func (m *Manager) DeleteResource(path string) error {
+	return m.DeleteResourceContext(context.Background(), path)
+}
+
+// DeleteResourceContext is like DeleteResource but runs storage operations with the given context
+func (m *Manager) DeleteResourceContext(ctx context.Context, path string) error {
+	return m.write(ctx, m.resourceChange(AuditDeleteResource, path), func(s Storage) error {
+		resource, err := m.getResourceByPath(s, path)
+		if err != nil {
+			return err
+		}
+
+		return s.DeleteResource(resource.ID)
+	})
 }
 
 // BuildPermissionString builds a permission string from resource path and action
@@ -1,20 +1,22 @@
 package privy
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
-func setupTestManager(t *testing.T) *Manager {
+func setupTestManager(t *testing.T, opts ...ManagerOption) *Manager {
 	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
 	if err != nil {
 		t.Fatalf("failed to open database: %v", err)
 	}
 
 	storage := NewGormStorage(db)
-	m := CreateManager(WithStorage(storage))
+	m := CreateManager(append([]ManagerOption{WithStorage(storage)}, opts...)...)
 
 	return m
 }
@@ -83,6 +85,39 @@ func TestManager_CreateResourceWithSubResources(t *testing.T) {
 	}
 }
 
+func TestManager_CreateResourceIsAtomic(t *testing.T) {
+	managers := map[string]*Manager{
+		"gorm":   setupTestManager(t),
+		"memory": CreateManager(WithStorage(NewMemoryStorage())),
+	}
+
+	for name, m := range managers {
+		t.Run(name, func(t *testing.T) {
+			_, err := m.CreateResource(ResourceConfig{
+				Key:     "article",
+				Actions: []Action{DefineAction("read", "Read", "Read article content")},
+				SubResources: []Resource{
+					{
+						Key: "comment",
+						Actions: []Action{
+							DefineAction("read", "Read", "Read comment content"),
+							DefineAction("read", "Read", "Duplicate action"),
+						},
+					},
+				},
+			})
+			if err == nil {
+				t.Fatal("expected error for duplicate action")
+			}
+
+			// Nothing of the partially created tree may be left behind
+			if _, err := m.GetResource("article"); err != ErrResourceNotFound {
+				t.Errorf("expected ErrResourceNotFound, got %v", err)
+			}
+		})
+	}
+}
+
 func TestManager_AddActions(t *testing.T) {
 	m := setupTestManager(t)
 
@@ -156,6 +191,156 @@ func TestManager_CreateResources(t *testing.T) {
 	}
 }
 
+func TestManager_CreateResourceDeepTree(t *testing.T) {
+	m := setupTestManager(t)
+
+	_, err := m.CreateResource(ResourceConfig{
+		Key:     "article",
+		Actions: []Action{DefineAction("read", "Read", "Read article content")},
+		SubResources: []Resource{
+			{
+				Key:     "comment",
+				Actions: []Action{DefineAction("read", "Read", "Read comment content")},
+				SubResources: []Resource{
+					{
+						Key:     "tag",
+						Actions: []Action{DefineAction("assign", "Assign", "Assign a tag")},
+						SubResources: []Resource{
+							{Key: "color", Actions: []Action{DefineAction("set", "Set", "Set tag color")}},
+						},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create resource: %v", err)
+	}
+
+	tests := []struct {
+		path   string
+		action string
+	}{
+		{"article", "read"},
+		{"article.comment", "read"},
+		{"article.comment.tag", "assign"},
+		{"article.comment.tag.color", "set"},
+	}
+
+	for _, tt := range tests {
+		r, err := m.GetResource(tt.path)
+		if err != nil {
+			t.Fatalf("failed to get resource %q: %v", tt.path, err)
+		}
+		if len(r.Actions) != 1 || r.Actions[0].Key != tt.action {
+			t.Errorf("expected %q to have action %q, got %v", tt.path, tt.action, r.Actions)
+		}
+	}
+
+	// Extending an existing sub-resource descends into it
+	err = m.CreateResources("article", []Resource{
+		{
+			Key: "comment",
+			SubResources: []Resource{
+				{Key: "tag", Actions: []Action{DefineAction("remove", "Remove", "Remove a tag")}},
+				{Key: "reaction", Actions: []Action{DefineAction("add", "Add", "Add a reaction")}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create sub-resources: %v", err)
+	}
+
+	tag, err := m.GetResource("article.comment.tag")
+	if err != nil {
+		t.Fatalf("failed to get resource: %v", err)
+	}
+	if len(tag.Actions) != 2 {
+		t.Errorf("expected 2 actions on 'article.comment.tag', got %d", len(tag.Actions))
+	}
+	if _, err := m.GetResource("article.comment.reaction"); err != nil {
+		t.Errorf("failed to get resource 'article.comment.reaction': %v", err)
+	}
+}
+
+func TestManager_CreateResourceInvalidKeys(t *testing.T) {
+	tests := []struct {
+		name   string
+		config ResourceConfig
+		err    error
+	}{
+		{"empty key", ResourceConfig{Key: ""}, ErrInvalidKey},
+		{"dotted key", ResourceConfig{Key: "article.comment"}, ErrInvalidKey},
+		{"wildcard key", ResourceConfig{Key: "*"}, ErrInvalidKey},
+		{"invalid action key", ResourceConfig{Key: "article", Actions: []Action{{Key: "read all"}}}, ErrInvalidKey},
+		{"duplicate action", ResourceConfig{Key: "article", Actions: []Action{{Key: "read"}, {Key: "read"}}}, ErrDuplicateKey},
+		{
+			"nested dotted key",
+			ResourceConfig{Key: "article", SubResources: []Resource{
+				{Key: "comment", SubResources: []Resource{{Key: "tag.color"}}},
+			}},
+			ErrInvalidKey,
+		},
+		{
+			"duplicate sub-resource",
+			ResourceConfig{Key: "article", SubResources: []Resource{{Key: "comment"}, {Key: "comment"}}},
+			ErrDuplicateKey,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := setupTestManager(t)
+
+			if _, err := m.CreateResource(tt.config); !errors.Is(err, tt.err) {
+				t.Fatalf("expected %v, got %v", tt.err, err)
+			}
+
+			resources, err := m.ListResources()
+			if err != nil {
+				t.Fatalf("failed to list resources: %v", err)
+			}
+			if len(resources) != 0 {
+				t.Errorf("expected no resources to be created, got %d", len(resources))
+			}
+		})
+	}
+}
+
+func TestManager_AddActionsInvalidKeys(t *testing.T) {
+	m := setupTestManager(t)
+
+	if _, err := m.CreateResource(ResourceConfig{Key: "article", Actions: []Action{{Key: "read"}}}); err != nil {
+		t.Fatalf("failed to create resource: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		actions []Action
+		err     error
+	}{
+		{"empty key", []Action{{Key: ""}}, ErrInvalidKey},
+		{"dotted key", []Action{{Key: "a.b"}}, ErrInvalidKey},
+		{"deny key", []Action{{Key: "!read"}}, ErrInvalidKey},
+		{"duplicate action", []Action{{Key: "write"}, {Key: "write"}}, ErrDuplicateKey},
+		{"existing action", []Action{{Key: "read"}}, ErrDuplicateKey},
+	}
+
+	for _, tt := range tests {
+		if err := m.AddActions("article", tt.actions); !errors.Is(err, tt.err) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.err, err)
+		}
+	}
+
+	r, err := m.GetResource("article")
+	if err != nil {
+		t.Fatalf("failed to get resource: %v", err)
+	}
+	if len(r.Actions) != 1 {
+		t.Errorf("expected only the original action, got %+v", r.Actions)
+	}
+}
+
 func TestManager_GetResourceByPath(t *testing.T) {
 	m := setupTestManager(t)
 
@@ -248,6 +433,39 @@ func TestManager_AssignPermissions(t *testing.T) {
 	}
 }
 
+func TestManager_AssignPermissionsValidatesSyntax(t *testing.T) {
+	m := setupTestManager(t)
+
+	_, err := m.CreateRole("editor", RoleConfig{
+		Name:        "Editor",
+		Permissions: []string{"article.*.read"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+
+	err = m.AssignPermissions("editor", []string{"article.update", "article.**.read"})
+	if !errors.Is(err, ErrInvalidPermission) {
+		t.Fatalf("expected ErrInvalidPermission, got %v", err)
+	}
+
+	role, err := m.GetRole("editor")
+	if err != nil {
+		t.Fatalf("failed to get role: %v", err)
+	}
+	if len(role.Permissions) != 1 {
+		t.Errorf("expected permissions to be unchanged, got %v", role.Permissions)
+	}
+
+	_, err = m.CreateRole("viewer", RoleConfig{
+		Name:        "Viewer",
+		Permissions: []string{"article..read"},
+	})
+	if !errors.Is(err, ErrInvalidPermission) {
+		t.Errorf("expected ErrInvalidPermission, got %v", err)
+	}
+}
+
 func TestManager_RemovePermissions(t *testing.T) {
 	m := setupTestManager(t)
 
@@ -392,3 +610,182 @@ func TestManager_ListRoles(t *testing.T) {
 		t.Errorf("expected 2 roles, got %d", len(roles))
 	}
 }
+
+func TestManager_RoleInheritance(t *testing.T) {
+	m := setupTestManager(t)
+
+	_, err := m.CreateRole("viewer", RoleConfig{
+		Name:        "Viewer",
+		Permissions: []string{"article.read"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create viewer role: %v", err)
+	}
+
+	_, err = m.CreateRole("editor", RoleConfig{
+		Name:        "Editor",
+		Permissions: []string{"article.update"},
+		Parents:     []string{"viewer"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create editor role: %v", err)
+	}
+
+	_, err = m.CreateRole("senior-editor", RoleConfig{
+		Name:        "Senior Editor",
+		Permissions: []string{"article.publish"},
+		Parents:     []string{"editor"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create senior-editor role: %v", err)
+	}
+
+	role, err := m.GetRole("senior-editor")
+	if err != nil {
+		t.Fatalf("failed to get role: %v", err)
+	}
+	if len(role.Parents) != 1 || role.Parents[0] != "editor" {
+		t.Errorf("expected parents [editor], got %v", role.Parents)
+	}
+
+	tests := []struct {
+		roleKey            string
+		requiredPermission string
+		expected           bool
+	}{
+		{"senior-editor", "article.publish", true},
+		{"senior-editor", "article.update", true},
+		{"senior-editor", "article.read", true},
+		{"editor", "article.read", true},
+		{"editor", "article.publish", false},
+		{"viewer", "article.update", false},
+	}
+
+	for _, tt := range tests {
+		result, err := m.CheckRolePermission(tt.roleKey, tt.requiredPermission)
+		if err != nil {
+			t.Fatalf("failed to check permission: %v", err)
+		}
+		if result != tt.expected {
+			t.Errorf("CheckRolePermission(%q, %q) = %v, want %v",
+				tt.roleKey, tt.requiredPermission, result, tt.expected)
+		}
+	}
+
+	result, err := m.CheckRolesPermission([]string{"nonexistent", "senior-editor"}, "article.read")
+	if err != nil {
+		t.Fatalf("failed to check roles permission: %v", err)
+	}
+	if !result {
+		t.Error("expected senior-editor to inherit 'article.read' permission")
+	}
+}
+
+func TestManager_RoleInheritanceCycle(t *testing.T) {
+	m := setupTestManager(t)
+
+	_, err := m.CreateRole("a", RoleConfig{Name: "A", Parents: []string{"a"}})
+	if err != ErrRoleCycle {
+		t.Errorf("expected ErrRoleCycle for self-inheritance, got %v", err)
+	}
+
+	_, err = m.CreateRole("a", RoleConfig{Name: "A", Parents: []string{"nonexistent"}})
+	if err != ErrRoleNotFound {
+		t.Errorf("expected ErrRoleNotFound for unknown parent, got %v", err)
+	}
+
+	if _, err := m.CreateRole("a", RoleConfig{Name: "A"}); err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+	if _, err := m.CreateRole("b", RoleConfig{Name: "B", Parents: []string{"a"}}); err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+	if _, err := m.CreateRole("c", RoleConfig{Name: "C", Parents: []string{"b"}}); err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+
+	if err := m.AddRoleParents("a", []string{"c"}); err != ErrRoleCycle {
+		t.Errorf("expected ErrRoleCycle, got %v", err)
+	}
+
+	role, err := m.GetRole("a")
+	if err != nil {
+		t.Fatalf("failed to get role: %v", err)
+	}
+	if len(role.Parents) != 0 {
+		t.Errorf("expected no parents after rejected update, got %v", role.Parents)
+	}
+}
+
+func TestManager_RemoveRoleParents(t *testing.T) {
+	m := setupTestManager(t)
+
+	if _, err := m.CreateRole("viewer", RoleConfig{Name: "Viewer", Permissions: []string{"article.read"}}); err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+	if _, err := m.CreateRole("editor", RoleConfig{Name: "Editor"}); err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+
+	if err := m.AddRoleParents("editor", []string{"viewer"}); err != nil {
+		t.Fatalf("failed to add role parents: %v", err)
+	}
+
+	hasPermission, err := m.CheckRolePermission("editor", "article.read")
+	if err != nil {
+		t.Fatalf("failed to check permission: %v", err)
+	}
+	if !hasPermission {
+		t.Error("expected editor to inherit 'article.read' permission")
+	}
+
+	if err := m.RemoveRoleParents("editor", []string{"viewer"}); err != nil {
+		t.Fatalf("failed to remove role parents: %v", err)
+	}
+
+	hasPermission, err = m.CheckRolePermission("editor", "article.read")
+	if err != nil {
+		t.Fatalf("failed to check permission: %v", err)
+	}
+	if hasPermission {
+		t.Error("expected editor not to have 'article.read' permission")
+	}
+}
+
+func TestManager_ContextCanceled(t *testing.T) {
+	managers := map[string]*Manager{
+		"gorm":   setupTestManager(t),
+		"memory": CreateManager(WithStorage(NewMemoryStorage())),
+	}
+
+	for name, m := range managers {
+		t.Run(name, func(t *testing.T) {
+			if _, err := m.CreateRoleContext(context.Background(), "editor", RoleConfig{Name: "Editor", Permissions: []string{"article"}}); err != nil {
+				t.Fatalf("failed to create role: %v", err)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			if _, err := m.CheckRolePermissionContext(ctx, "editor", "article.read"); !errors.Is(err, context.Canceled) {
+				t.Errorf("expected context.Canceled, got %v", err)
+			}
+			if _, err := m.CreateRoleContext(ctx, "viewer", RoleConfig{Name: "Viewer"}); !errors.Is(err, context.Canceled) {
+				t.Errorf("expected context.Canceled, got %v", err)
+			}
+
+			// The role must not have been created by the canceled call
+			if _, err := m.GetRole("viewer"); err != ErrRoleNotFound {
+				t.Errorf("expected ErrRoleNotFound, got %v", err)
+			}
+
+			hasPermission, err := m.CheckRolePermission("editor", "article.read")
+			if err != nil {
+				t.Fatalf("failed to check permission: %v", err)
+			}
+			if !hasPermission {
+				t.Error("expected editor to have 'article.read' permission")
+			}
+		})
+	}
+}
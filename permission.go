@@ -1,81 +1,448 @@
 package privy
 
-import "strings"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
 
-// CheckPermission checks if a given permission satisfies the required permission.
-// It supports hierarchical permission checking:
+// Permission strings are dot-separated segments, e.g. "article.comment.read".
+//
+// Besides literal segments, a granted permission may contain:
+//   - "*" as a whole segment, matching exactly one segment ("article.*.read", "*.read").
+//     A bare "*" grants everything.
+//   - "**" as the last segment, matching one or more trailing segments ("article.**").
+//   - the "!" prefix, turning the entry into a deny ("!article.delete").
+//
+// Matching is evaluated with the following precedence:
+//  1. If any deny entry matches the required permission, the check fails.
+//  2. Otherwise the check succeeds if any grant matches, whether through an exact,
+//     group, pattern or wildcard match. Specificity never matters between grants.
+//
+// A literal grant acts as a group, so "article" also covers "article.comment.read". A grant with
+// "*" segments matches exactly as many segments as it has: "article.*" covers "article.comment"
+// but not "article.comment.read", which takes "article.**". Deny entries always act as groups
+// and revoke everything under the permissions they match.
+
+var (
+	ErrInvalidPermission = errors.New("invalid permission")
+)
+
+const (
+	// WildcardSegment matches exactly one permission segment
+	WildcardSegment = "*"
+
+	// RecursiveWildcardSegment matches one or more trailing permission segments
+	RecursiveWildcardSegment = "**"
+)
+
+// DenyPrefix marks a permission as a deny entry (e.g. "!article.delete").
+// A deny entry revokes the permission it names, including everything under it,
+// and always overrides grants.
+const DenyPrefix = "!"
+
+// IsDenyPermission reports whether a permission is a deny entry
+func IsDenyPermission(permission string) bool {
+	return strings.HasPrefix(permission, DenyPrefix)
+}
+
+// MatchMode controls how granted permissions are matched against a required permission
+type MatchMode int
+
+const (
+	// MatchLegacy accepts exact, parent-group and "*" grants, and also grants that are
+	// children of the required permission (e.g. "infrastructure.vm.start" satisfies "infrastructure").
+	MatchLegacy MatchMode = iota
+
+	// MatchStrict only accepts exact, parent-group and "*" grants, so a narrow grant
+	// never satisfies a broader requirement.
+	MatchStrict
+)
+
+// String returns the name of the match mode
+func (mode MatchMode) String() string {
+	switch mode {
+	case MatchLegacy:
+		return "legacy"
+	case MatchStrict:
+		return "strict"
+	default:
+		return "unknown"
+	}
+}
+
+// CheckPermission checks if a given permission satisfies the required permission
+// using the legacy matching mode. It supports hierarchical permission checking:
 // - Exact match: "user.create" == "user.create"
 // - Group match: "user" includes "user.create"
 // - Hierarchical match: "infrastructure" includes "infrastructure.vm.start"
+// - Child match: "infrastructure.vm.start" satisfies "infrastructure.vm"
 //
 // Examples:
 //   CheckPermission("user.create", "user.create")              // true (exact match)
 //   CheckPermission("user.create", "user")                     // true (group match)
-//   CheckPermission("infrastructure.vm", "infrastructure.vm.start") // false (required is more specific than given)
+//   CheckPermission("infrastructure.vm", "infrastructure.vm.start") // true (child match)
 //   CheckPermission("infrastructure", "infrastructure.vm.stop") // true (hierarchical match)
 //   CheckPermission("user.delete", "user.update")              // false (different permissions)
+//
+// Deny entries never satisfy a permission; use CheckPermissions to apply them.
+// Use CheckPermissionStrict to disable child matches.
 func CheckPermission(requiredPermission, givenPermission string) bool {
+	return checkPermission(requiredPermission, givenPermission, MatchLegacy)
+}
+
+// CheckPermissionStrict checks if a given permission satisfies the required permission
+// using the strict matching mode. Only exact, parent-group and "*" grants match:
+//
+//   CheckPermissionStrict("user.create", "user")                     // true (group match)
+//   CheckPermissionStrict("infrastructure.vm", "infrastructure.vm.start") // false (given is narrower)
+func CheckPermissionStrict(requiredPermission, givenPermission string) bool {
+	return checkPermission(requiredPermission, givenPermission, MatchStrict)
+}
+
+// MatchKind describes the rule through which a grant satisfies a required permission
+type MatchKind string
+
+const (
+	// MatchNone means the grant does not satisfy the required permission
+	MatchNone MatchKind = ""
+
+	// MatchExact means the grant equals the required permission
+	MatchExact MatchKind = "exact"
+
+	// MatchGroup means the grant is a parent/group of the required permission
+	MatchGroup MatchKind = "group"
+
+	// MatchChild means the grant is a child of the required permission (legacy mode only)
+	MatchChild MatchKind = "child"
+
+	// MatchWildcard means the grant is "*"
+	MatchWildcard MatchKind = "wildcard"
+
+	// MatchPattern means the grant is a segment pattern such as "article.*.read"
+	MatchPattern MatchKind = "pattern"
+)
+
+// checkPermission checks a single grant against the required permission using the given mode
+func checkPermission(requiredPermission, givenPermission string, mode MatchMode) bool {
+	return classifyPermission(requiredPermission, givenPermission, mode) != MatchNone
+}
+
+// classifyPermission reports the rule through which a grant satisfies the required permission
+func classifyPermission(requiredPermission, givenPermission string, mode MatchMode) MatchKind {
+	// Deny entries never grant anything
+	if IsDenyPermission(givenPermission) {
+		return MatchNone
+	}
+
 	// Wildcard support - "*" grants all permissions
 	if givenPermission == "*" {
-		return true
+		return MatchWildcard
+	}
+
+	// Segment patterns such as "article.*.read" or "article.**"
+	if strings.Contains(givenPermission, WildcardSegment) {
+		return matchPattern(parsePermission(requiredPermission), parsePermission(givenPermission), mode)
 	}
 
 	// Exact match
 	if requiredPermission == givenPermission {
-		return true
+		return MatchExact
 	}
 
 	// Check if given permission is a parent/group of the required permission
 	// e.g., given "user" should match required "user.create"
 	// e.g., given "infrastructure" should match required "infrastructure.vm.start"
 	if strings.HasPrefix(requiredPermission, givenPermission+".") {
-		return true
+		return MatchGroup
 	}
 
 	// Check if required permission is a parent/group of the given permission
 	// e.g., given "infrastructure.vm.start" should match required "infrastructure.vm"
 	// e.g., given "infrastructure.vm.start" should match required "infrastructure"
-	if strings.HasPrefix(givenPermission, requiredPermission+".") {
+	if mode == MatchLegacy && strings.HasPrefix(givenPermission, requiredPermission+".") {
+		return MatchChild
+	}
+
+	return MatchNone
+}
+
+// parsePermission splits a permission string into its segments
+func parsePermission(permission string) []string {
+	return strings.Split(permission, ".")
+}
+
+// matchPattern checks if the segments of a granted pattern cover the required segments.
+// Each granted segment must equal the required segment or be "*"; a trailing "**" covers
+// one or more remaining segments, and otherwise the pattern must be as long as the requirement.
+// In legacy mode, a requirement that is a prefix of the pattern matches as well.
+func matchPattern(required, given []string, mode MatchMode) MatchKind {
+	for i, segment := range given {
+		if i == len(required) {
+			// Required permission is a parent/group of the given pattern
+			if mode == MatchLegacy {
+				return MatchChild
+			}
+			return MatchNone
+		}
+
+		if segment == RecursiveWildcardSegment {
+			return MatchPattern
+		}
+
+		if segment != WildcardSegment && segment != required[i] {
+			return MatchNone
+		}
+	}
+
+	// Unlike a literal grant, a pattern is not a group of longer permissions
+	if len(given) < len(required) {
+		return MatchNone
+	}
+	return MatchPattern
+}
+
+// ValidatePermission checks the syntax of a permission string, including deny entries
+// and wildcard segments. It returns an error wrapping ErrInvalidPermission.
+func ValidatePermission(permission string) error {
+	body := strings.TrimPrefix(permission, DenyPrefix)
+	if body == "" {
+		return fmt.Errorf("%w %q: empty permission", ErrInvalidPermission, permission)
+	}
+
+	segments := parsePermission(body)
+	for i, segment := range segments {
+		switch {
+		case segment == "":
+			return fmt.Errorf("%w %q: empty segment", ErrInvalidPermission, permission)
+		case segment == WildcardSegment:
+		case segment == RecursiveWildcardSegment:
+			if i != len(segments)-1 {
+				return fmt.Errorf("%w %q: %q is only allowed as the last segment",
+					ErrInvalidPermission, permission, RecursiveWildcardSegment)
+			}
+		case strings.Contains(segment, WildcardSegment):
+			return fmt.Errorf("%w %q: wildcards must be a whole segment", ErrInvalidPermission, permission)
+		case strings.Contains(segment, DenyPrefix) || strings.ContainsAny(segment, " \t\r\n"):
+			return fmt.Errorf("%w %q: invalid character in segment %q", ErrInvalidPermission, permission, segment)
+		}
+	}
+
+	return nil
+}
+
+// validatePermissions checks the syntax of every permission in the list
+func validatePermissions(permissions []string) error {
+	for _, p := range permissions {
+		if err := ValidatePermission(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkDeny checks if a deny entry (without its prefix) revokes the required permission.
+// A deny applies to the permissions it matches and everything under them, and "*" denies all,
+// regardless of the match mode.
+func checkDeny(requiredPermission, deniedPermission string) bool {
+	if deniedPermission == WildcardSegment {
 		return true
 	}
 
-	return false
+	required := parsePermission(requiredPermission)
+	denied := parsePermission(deniedPermission)
+	if len(denied) > len(required) {
+		return false
+	}
+
+	for i, segment := range denied {
+		if segment == RecursiveWildcardSegment {
+			return true
+		}
+		if segment != WildcardSegment && segment != required[i] {
+			return false
+		}
+	}
+	return true
 }
 
 // CheckPermissions checks if any of the given permissions satisfies the required permission
+// using the legacy matching mode. Deny entries (e.g. "!article.delete") override any grant in the list.
 func CheckPermissions(requiredPermission string, givenPermissions []string) bool {
+	return checkPermissions(requiredPermission, givenPermissions, MatchLegacy)
+}
+
+// CheckPermissionsStrict is like CheckPermissions but uses the strict matching mode
+func CheckPermissionsStrict(requiredPermission string, givenPermissions []string) bool {
+	return checkPermissions(requiredPermission, givenPermissions, MatchStrict)
+}
+
+// checkPermissions checks a list of grants and deny entries against the required permission
+func checkPermissions(requiredPermission string, givenPermissions []string, mode MatchMode) bool {
+	for _, given := range givenPermissions {
+		if IsDenyPermission(given) && checkDeny(requiredPermission, strings.TrimPrefix(given, DenyPrefix)) {
+			return false
+		}
+	}
+
 	for _, given := range givenPermissions {
-		if CheckPermission(requiredPermission, given) {
+		if checkPermission(requiredPermission, given, mode) {
 			return true
 		}
 	}
 	return false
 }
 
-// CheckRolePermission checks if a role has the required permission
+// CheckRolePermission checks if a role has the required permission, including permissions
+// inherited from its parent roles.
+// It uses context.Background internally; to specify the context, use CheckRolePermissionContext.
 func (m *Manager) CheckRolePermission(roleKey, requiredPermission string) (bool, error) {
-	role, err := m.storage.GetRole(roleKey)
+	return m.CheckRolePermissionContext(context.Background(), roleKey, requiredPermission)
+}
+
+// CheckRolePermissionContext is like CheckRolePermission but runs storage operations with the given context
+func (m *Manager) CheckRolePermissionContext(ctx context.Context, roleKey, requiredPermission string) (bool, error) {
+	start := time.Now()
+
+	set, err := m.rolePermissionSet(m.storage.WithContext(ctx), roleKey)
 	if err != nil {
 		return false, err
 	}
 
-	return CheckPermissions(requiredPermission, role.Permissions), nil
+	e := evaluate([]roleSet{{role: roleKey, set: set}}, parsePermission(requiredPermission))
+	m.logDecision(ctx, start, []string{roleKey}, nil, requiredPermission, e)
+
+	return e.allowed(), nil
 }
 
-// CheckRolesPermission checks if any of the given roles has the required permission
+// roleGrant is a permission entry together with the key of the role that defines it
+type roleGrant struct {
+	role       string
+	permission string
+}
+
+// resolveRoleGrants collects the permission entries of a role and all roles it inherits from,
+// in breadth-first order starting with the role itself
+func (m *Manager) resolveRoleGrants(s Storage, role *Role) ([]roleGrant, error) {
+	grants := make([]roleGrant, 0, len(role.Permissions))
+	for _, p := range role.Permissions {
+		grants = append(grants, roleGrant{role: role.Key, permission: p})
+	}
+
+	visited := map[string]bool{role.Key: true}
+	pending := append([]string(nil), role.Parents...)
+
+	for len(pending) > 0 {
+		key := pending[0]
+		pending = pending[1:]
+
+		if visited[key] {
+			continue
+		}
+		visited[key] = true
+
+		parent, err := s.GetRole(key)
+		if err != nil {
+			// Skip parents that have been removed concurrently
+			if err == ErrRoleNotFound {
+				continue
+			}
+			return nil, err
+		}
+
+		for _, p := range parent.Permissions {
+			grants = append(grants, roleGrant{role: parent.Key, permission: p})
+		}
+		pending = append(pending, parent.Parents...)
+	}
+
+	return grants, nil
+}
+
+// CheckRolesPermission checks if any of the given roles has the required permission.
+// A deny entry in any of the roles overrides grants from all other roles.
+// It uses context.Background internally; to specify the context, use CheckRolesPermissionContext.
 func (m *Manager) CheckRolesPermission(roleKeys []string, requiredPermission string) (bool, error) {
+	return m.CheckRolesPermissionContext(context.Background(), roleKeys, requiredPermission)
+}
+
+// CheckRolesPermissionContext is like CheckRolesPermission but runs storage operations with the given context
+func (m *Manager) CheckRolesPermissionContext(ctx context.Context, roleKeys []string, requiredPermission string) (bool, error) {
+	start := time.Now()
+
+	sets, skipped, err := m.rolePermissionSets(m.storage.WithContext(ctx), roleKeys)
+	if err != nil {
+		return false, err
+	}
+
+	e := evaluate(sets, parsePermission(requiredPermission))
+	m.logDecision(ctx, start, roleKeys, skipped, requiredPermission, e)
+
+	return e.allowed(), nil
+}
+
+// roleSet is the compiled permissions of a requested role
+type roleSet struct {
+	role string
+	set  *PermissionSet
+}
+
+// rolePermissionSets returns the compiled permissions of the given roles together with the
+// keys of roles that don't exist, which are skipped
+func (m *Manager) rolePermissionSets(s Storage, roleKeys []string) ([]roleSet, []string, error) {
+	sets := make([]roleSet, 0, len(roleKeys))
+	var skipped []string
 	for _, roleKey := range roleKeys {
-		hasPermission, err := m.CheckRolePermission(roleKey, requiredPermission)
+		set, err := m.rolePermissionSet(s, roleKey)
 		if err != nil {
-			// Skip roles that don't exist
 			if err == ErrRoleNotFound {
+				skipped = append(skipped, roleKey)
 				continue
 			}
-			return false, err
+			return nil, nil, err
+		}
+		sets = append(sets, roleSet{role: roleKey, set: set})
+	}
+
+	return sets, skipped, nil
+}
+
+// evaluation is the outcome of checking a permission against the sets of several roles
+type evaluation struct {
+	// grant is the first grant satisfying the permission and grantRole the requested role holding it
+	grant     *roleGrant
+	grantRole string
+	// deny is the first deny entry revoking the permission and denyRole the requested role holding it
+	deny     *roleGrant
+	denyRole string
+}
+
+// allowed reports whether a grant satisfies the permission and no deny entry revokes it
+func (e evaluation) allowed() bool {
+	return e.grant != nil && e.deny == nil
+}
+
+// evaluate checks the required permission against the sets of the given roles. A deny entry
+// in any role overrides grants from all roles.
+func evaluate(sets []roleSet, required []string) evaluation {
+	var e evaluation
+	for _, rs := range sets {
+		if e.deny == nil {
+			if e.deny = rs.set.deny(required); e.deny != nil {
+				e.denyRole = rs.role
+			}
+		}
+		if e.grant == nil {
+			if e.grant = rs.set.grant(required); e.grant != nil {
+				e.grantRole = rs.role
+			}
 		}
-		if hasPermission {
-			return true, nil
+		if e.deny != nil && e.grant != nil {
+			break
 		}
 	}
-	return false, nil
+
+	return e
 }
@@ -0,0 +1,163 @@
+package privy
+
+import "strings"
+
+// PermissionSet is a compiled, immutable set of grants and deny entries. It answers the same
+// question as CheckPermissions, but walks a segment trie instead of scanning every entry, so
+// a lookup costs O(depth) for literal grants regardless of how many grants the set holds.
+//
+// A PermissionSet is safe for concurrent use. Use it to check permissions carried in tokens:
+//
+//	set := privy.CompilePermissionSet(claims.Permissions, privy.MatchStrict)
+//	if set.Allows("article.update") { ... }
+type PermissionSet struct {
+	grants *permissionNode
+	denies *permissionNode
+	mode   MatchMode
+}
+
+// permissionNode is a trie node for one permission segment
+type permissionNode struct {
+	children map[string]*permissionNode
+	// wildcard is the node for a "*" segment
+	wildcard *permissionNode
+	// terminal is the first entry ending at this node, which covers everything under it
+	terminal *roleGrant
+	// exact is the first grant pattern ending at this node, which covers the node only
+	exact *roleGrant
+	// recursive is the first entry with a "**" segment following this node
+	recursive *roleGrant
+	// descendant is the first entry passing below this node, reported for child matches
+	descendant *roleGrant
+}
+
+// CompilePermissionSet compiles grants and deny entries (prefixed with "!") into a PermissionSet
+// using the given match mode for grants. Deny entries always match like in strict mode.
+func CompilePermissionSet(permissions []string, mode MatchMode) *PermissionSet {
+	grants := make([]roleGrant, 0, len(permissions))
+	for _, p := range permissions {
+		grants = append(grants, roleGrant{permission: p})
+	}
+	return compileGrants(grants, mode)
+}
+
+// compileGrants compiles permission entries remembering the role defining each of them
+func compileGrants(grants []roleGrant, mode MatchMode) *PermissionSet {
+	set := &PermissionSet{
+		grants: &permissionNode{},
+		denies: &permissionNode{},
+		mode:   mode,
+	}
+
+	for i := range grants {
+		g := &grants[i]
+		if IsDenyPermission(g.permission) {
+			body := strings.TrimPrefix(g.permission, DenyPrefix)
+			// A doubled prefix never matches anything, as in CheckPermissions
+			if !IsDenyPermission(body) {
+				set.denies.insert(parsePermission(body), g, false)
+			}
+			continue
+		}
+		// Grant patterns match exactly as many segments as they have, except a bare "*"
+		exact := strings.Contains(g.permission, WildcardSegment) && g.permission != WildcardSegment
+		set.grants.insert(parsePermission(g.permission), g, exact)
+	}
+
+	return set
+}
+
+// insert adds the segments of an entry below the node. An exact entry does not cover the
+// permissions under it.
+func (n *permissionNode) insert(segments []string, g *roleGrant, exact bool) {
+	node := n
+	for _, segment := range segments {
+		switch segment {
+		case RecursiveWildcardSegment:
+			// "**" covers everything that follows, so later segments do not matter
+			if node.recursive == nil {
+				node.recursive = g
+			}
+			return
+		case WildcardSegment:
+			if node.wildcard == nil {
+				node.wildcard = &permissionNode{}
+			}
+			node = node.wildcard
+		default:
+			if node.children == nil {
+				node.children = make(map[string]*permissionNode)
+			}
+			child, ok := node.children[segment]
+			if !ok {
+				child = &permissionNode{}
+				node.children[segment] = child
+			}
+			node = child
+		}
+		if node.descendant == nil {
+			node.descendant = g
+		}
+	}
+	switch {
+	case exact && node.exact == nil:
+		node.exact = g
+	case !exact && node.terminal == nil:
+		node.terminal = g
+	}
+}
+
+// match returns an entry below the node covering the required segments from index i, or nil.
+// In legacy mode an entry that continues beyond the required permission matches as well.
+func (n *permissionNode) match(required []string, i int, mode MatchMode) *roleGrant {
+	if i == len(required) {
+		if n.terminal != nil {
+			return n.terminal
+		}
+		if n.exact != nil {
+			return n.exact
+		}
+		if mode != MatchLegacy {
+			return nil
+		}
+		// Every entry passing below the node is a child of the required permission
+		if n.descendant != nil {
+			return n.descendant
+		}
+		return n.recursive
+	}
+
+	if n.terminal != nil {
+		return n.terminal
+	}
+	if n.recursive != nil {
+		return n.recursive
+	}
+
+	if child, ok := n.children[required[i]]; ok {
+		if g := child.match(required, i+1, mode); g != nil {
+			return g
+		}
+	}
+
+	if n.wildcard != nil {
+		return n.wildcard.match(required, i+1, mode)
+	}
+	return nil
+}
+
+// Allows reports whether the set grants the required permission and no deny entry revokes it
+func (ps *PermissionSet) Allows(requiredPermission string) bool {
+	required := parsePermission(requiredPermission)
+	return ps.deny(required) == nil && ps.grant(required) != nil
+}
+
+// deny returns the deny entry of the set revoking the required permission, or nil
+func (ps *PermissionSet) deny(required []string) *roleGrant {
+	return ps.denies.match(required, 0, MatchStrict)
+}
+
+// grant returns the grant of the set satisfying the required permission, or nil
+func (ps *PermissionSet) grant(required []string) *roleGrant {
+	return ps.grants.match(required, 0, ps.mode)
+}
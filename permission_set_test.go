@@ -0,0 +1,131 @@
+package privy
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestPermissionSet_Allows(t *testing.T) {
+	tests := []struct {
+		permissions []string
+		required    string
+		mode        MatchMode
+		expected    bool
+	}{
+		{[]string{"article.read"}, "article.read", MatchLegacy, true},
+		{[]string{"article"}, "article.comment.read", MatchStrict, true},
+		{[]string{"article.comment.read"}, "article", MatchLegacy, true},
+		{[]string{"article.comment.read"}, "article", MatchStrict, false},
+		{[]string{"*"}, "user.delete", MatchStrict, true},
+		{[]string{"article.*.read"}, "article.comment.read", MatchStrict, true},
+		{[]string{"article.*.read"}, "article.comment.delete", MatchStrict, false},
+		{[]string{"article.**"}, "article.comment.read", MatchStrict, true},
+		{[]string{"article.**"}, "article", MatchStrict, false},
+		{[]string{"article.*"}, "article.comment", MatchStrict, true},
+		{[]string{"article.*"}, "article.comment.read", MatchLegacy, false},
+		{[]string{"article.*.read"}, "article.comment.read.draft", MatchLegacy, false},
+		{[]string{"article", "!article.*"}, "article.comment.read", MatchLegacy, false},
+		{[]string{"article", "!article.delete"}, "article.delete", MatchLegacy, false},
+		{[]string{"article", "!article.delete"}, "article.update", MatchLegacy, true},
+		{[]string{"*", "!*"}, "article.read", MatchLegacy, false},
+		{[]string{"article.read", "!article.read.draft"}, "article", MatchLegacy, true},
+		{[]string{}, "article.read", MatchLegacy, false},
+	}
+
+	for _, tt := range tests {
+		set := CompilePermissionSet(tt.permissions, tt.mode)
+		if got := set.Allows(tt.required); got != tt.expected {
+			t.Errorf("CompilePermissionSet(%v, %s).Allows(%q) = %v, want %v",
+				tt.permissions, tt.mode, tt.required, got, tt.expected)
+		}
+	}
+}
+
+func TestPermissionSet_MatchesCheckPermissions(t *testing.T) {
+	entries := []string{
+		"*", "**", "article", "article.read", "article.comment", "article.comment.read",
+		"article.*", "article.*.read", "*.read", "article.**", "article.**.read", "user.create",
+		"!article", "!article.delete", "!article.comment.*", "!*", "!!article", "ar*icle",
+	}
+	required := []string{
+		"article", "article.read", "article.delete", "article.comment", "article.comment.read",
+		"article.comment.delete", "article.comment.tag.read", "user", "user.create", "ar*icle", "read",
+	}
+
+	// Compare every pair and triple of entries against the reference implementation
+	var sets [][]string
+	for i := range entries {
+		sets = append(sets, []string{entries[i]})
+		for j := i + 1; j < len(entries); j++ {
+			sets = append(sets, []string{entries[i], entries[j]})
+			for k := j + 1; k < len(entries); k++ {
+				sets = append(sets, []string{entries[i], entries[j], entries[k]})
+			}
+		}
+	}
+
+	for _, mode := range []MatchMode{MatchLegacy, MatchStrict} {
+		for _, permissions := range sets {
+			set := CompilePermissionSet(permissions, mode)
+			for _, r := range required {
+				if got, want := set.Allows(r), checkPermissions(r, permissions, mode); got != want {
+					t.Errorf("%s: CompilePermissionSet(%v).Allows(%q) = %v, checkPermissions = %v",
+						mode, permissions, r, got, want)
+				}
+
+				// The reported entries must match on their own; a matching deny entry revokes even "*"
+				segments := parsePermission(r)
+				if g := set.grant(segments); g != nil && !checkPermission(r, g.permission, mode) {
+					t.Errorf("%s: %v reports grant %q for %q, which does not match", mode, permissions, g.permission, r)
+				}
+				if d := set.deny(segments); d != nil && checkPermissions(r, []string{"*", d.permission}, mode) {
+					t.Errorf("%s: %v reports deny %q for %q, which does not match", mode, permissions, d.permission, r)
+				}
+			}
+		}
+	}
+}
+
+func TestPermissionSet_RolesDenyAcrossRoles(t *testing.T) {
+	m := setupTestManager(t)
+
+	if _, err := m.CreateRole("editor", RoleConfig{Permissions: []string{"article"}}); err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+	if _, err := m.CreateRole("restricted", RoleConfig{Permissions: []string{"!article.delete"}}); err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+
+	hasPermission, err := m.CheckRolesPermission([]string{"editor", "restricted"}, "article.delete")
+	if err != nil {
+		t.Fatalf("failed to check permission: %v", err)
+	}
+	if hasPermission {
+		t.Error("expected deny in one role to override grants from another role")
+	}
+}
+
+func BenchmarkPermissionSet_Allows(b *testing.B) {
+	permissions := make([]string, 0, 500)
+	for i := 0; i < 500; i++ {
+		permissions = append(permissions, fmt.Sprintf("service%d.resource.read", i))
+	}
+
+	set := CompilePermissionSet(permissions, MatchLegacy)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		set.Allows("service499.resource.read")
+	}
+}
+
+func BenchmarkCheckPermissions(b *testing.B) {
+	permissions := make([]string, 0, 500)
+	for i := 0; i < 500; i++ {
+		permissions = append(permissions, fmt.Sprintf("service%d.resource.read", i))
+	}
+
+	for i := 0; i < b.N; i++ {
+		CheckPermissions("service499.resource.read", permissions)
+	}
+}
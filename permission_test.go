@@ -1,6 +1,9 @@
 package privy
 
-import "testing"
+import (
+	"errors"
+	"testing"
+)
 
 func TestCheckPermission(t *testing.T) {
 	tests := []struct {
@@ -81,6 +84,12 @@ func TestCheckPermission(t *testing.T) {
 			givenPermission:    "*",
 			expected:           true,
 		},
+		{
+			name:               "deny entry never grants",
+			requiredPermission: "article.delete",
+			givenPermission:    "!article.delete",
+			expected:           false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -94,6 +103,258 @@ func TestCheckPermission(t *testing.T) {
 	}
 }
 
+func TestCheckPermissionPatterns(t *testing.T) {
+	tests := []struct {
+		name               string
+		requiredPermission string
+		givenPermission    string
+		expected           bool
+		expectedStrict     bool
+	}{
+		{
+			name:               "segment wildcard matches one segment",
+			requiredPermission: "article.comment.read",
+			givenPermission:    "article.*.read",
+			expected:           true,
+			expectedStrict:     true,
+		},
+		{
+			name:               "segment wildcard does not match other actions",
+			requiredPermission: "article.comment.delete",
+			givenPermission:    "article.*.read",
+			expected:           false,
+			expectedStrict:     false,
+		},
+		{
+			name:               "segment wildcard requires a segment",
+			requiredPermission: "article.read",
+			givenPermission:    "article.*.read",
+			expected:           true, // "article.read.read" is a child of the requirement
+			expectedStrict:     false,
+		},
+		{
+			name:               "leading wildcard",
+			requiredPermission: "user.read",
+			givenPermission:    "*.read",
+			expected:           true,
+			expectedStrict:     true,
+		},
+		{
+			name:               "pattern does not act as a group",
+			requiredPermission: "article.comment.read",
+			givenPermission:    "*.comment",
+			expected:           false,
+			expectedStrict:     false,
+		},
+		{
+			name:               "trailing wildcard matches one segment",
+			requiredPermission: "article.comment",
+			givenPermission:    "article.*",
+			expected:           true,
+			expectedStrict:     true,
+		},
+		{
+			name:               "trailing wildcard does not match deeper permissions",
+			requiredPermission: "article.comment.read",
+			givenPermission:    "article.*",
+			expected:           false,
+			expectedStrict:     false,
+		},
+		{
+			name:               "recursive wildcard matches deeper permissions",
+			requiredPermission: "article.comment.read",
+			givenPermission:    "article.**",
+			expected:           true,
+			expectedStrict:     true,
+		},
+		{
+			name:               "inner wildcard does not match deeper permissions",
+			requiredPermission: "article.comment.read.draft",
+			givenPermission:    "article.*.read",
+			expected:           false,
+			expectedStrict:     false,
+		},
+		{
+			name:               "recursive wildcard matches deep permissions",
+			requiredPermission: "article.comment.tag.read",
+			givenPermission:    "article.**",
+			expected:           true,
+			expectedStrict:     true,
+		},
+		{
+			name:               "recursive wildcard does not match the resource itself",
+			requiredPermission: "article",
+			givenPermission:    "article.**",
+			expected:           true,
+			expectedStrict:     false,
+		},
+		{
+			name:               "recursive wildcard on a different resource",
+			requiredPermission: "user.read",
+			givenPermission:    "article.**",
+			expected:           false,
+			expectedStrict:     false,
+		},
+		{
+			name:               "pattern is child of required",
+			requiredPermission: "article",
+			givenPermission:    "article.*.read",
+			expected:           true,
+			expectedStrict:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := CheckPermission(tt.requiredPermission, tt.givenPermission)
+			if result != tt.expected {
+				t.Errorf("CheckPermission(%q, %q) = %v, want %v",
+					tt.requiredPermission, tt.givenPermission, result, tt.expected)
+			}
+
+			result = CheckPermissionStrict(tt.requiredPermission, tt.givenPermission)
+			if result != tt.expectedStrict {
+				t.Errorf("CheckPermissionStrict(%q, %q) = %v, want %v",
+					tt.requiredPermission, tt.givenPermission, result, tt.expectedStrict)
+			}
+		})
+	}
+}
+
+func TestCheckPermissionsPatternDeny(t *testing.T) {
+	given := []string{"article", "!*.delete"}
+
+	if CheckPermissions("article.delete", given) {
+		t.Error("expected '!*.delete' to deny 'article.delete'")
+	}
+	if !CheckPermissions("article.update", given) {
+		t.Error("expected 'article' to grant 'article.update'")
+	}
+	if !CheckPermissions("article.comment.delete", given) {
+		t.Error("expected '!*.delete' not to deny 'article.comment.delete'")
+	}
+
+	// A deny pattern revokes everything under the permissions it matches
+	if CheckPermissions("article.comment.read", []string{"article", "!article.*"}) {
+		t.Error("expected '!article.*' to deny 'article.comment.read'")
+	}
+}
+
+func TestValidatePermission(t *testing.T) {
+	tests := []struct {
+		permission string
+		valid      bool
+	}{
+		{"article.read", true},
+		{"article", true},
+		{"*", true},
+		{"**", true},
+		{"article.*.read", true},
+		{"*.read", true},
+		{"article.**", true},
+		{"!article.delete", true},
+		{"!*", true},
+		{"", false},
+		{"!", false},
+		{"article..read", false},
+		{".article", false},
+		{"article.", false},
+		{"article.**.read", false},
+		{"art*.read", false},
+		{"article.***", false},
+		{"!!article", false},
+		{"article.re ad", false},
+	}
+
+	for _, tt := range tests {
+		err := ValidatePermission(tt.permission)
+		if tt.valid && err != nil {
+			t.Errorf("ValidatePermission(%q) returned unexpected error: %v", tt.permission, err)
+		}
+		if !tt.valid && !errors.Is(err, ErrInvalidPermission) {
+			t.Errorf("ValidatePermission(%q) = %v, want ErrInvalidPermission", tt.permission, err)
+		}
+	}
+}
+
+func TestCheckPermissionStrict(t *testing.T) {
+	tests := []struct {
+		name               string
+		requiredPermission string
+		givenPermission    string
+		expected           bool
+	}{
+		{
+			name:               "exact match",
+			requiredPermission: "user.create",
+			givenPermission:    "user.create",
+			expected:           true,
+		},
+		{
+			name:               "group match - given is parent",
+			requiredPermission: "infrastructure.vm.start",
+			givenPermission:    "infrastructure",
+			expected:           true,
+		},
+		{
+			name:               "given is child should fail",
+			requiredPermission: "infrastructure",
+			givenPermission:    "infrastructure.vm.start",
+			expected:           false,
+		},
+		{
+			name:               "given is direct child should fail",
+			requiredPermission: "infrastructure.vm",
+			givenPermission:    "infrastructure.vm.start",
+			expected:           false,
+		},
+		{
+			name:               "wildcard grants all permissions",
+			requiredPermission: "infrastructure",
+			givenPermission:    "*",
+			expected:           true,
+		},
+		{
+			name:               "partial match should fail",
+			requiredPermission: "user",
+			givenPermission:    "username",
+			expected:           false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := CheckPermissionStrict(tt.requiredPermission, tt.givenPermission)
+			if result != tt.expected {
+				t.Errorf("CheckPermissionStrict(%q, %q) = %v, want %v",
+					tt.requiredPermission, tt.givenPermission, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCheckPermissionsStrict(t *testing.T) {
+	given := []string{"article.read", "article.comment", "!article.comment.delete"}
+
+	tests := []struct {
+		requiredPermission string
+		expected           bool
+	}{
+		{"article.read", true},
+		{"article.comment.create", true},
+		{"article.comment.delete", false},
+		{"article", false},
+	}
+
+	for _, tt := range tests {
+		result := CheckPermissionsStrict(tt.requiredPermission, given)
+		if result != tt.expected {
+			t.Errorf("CheckPermissionsStrict(%q, %v) = %v, want %v",
+				tt.requiredPermission, given, result, tt.expected)
+		}
+	}
+}
+
 func TestCheckPermissions(t *testing.T) {
 	tests := []struct {
 		name               string
@@ -125,6 +386,42 @@ func TestCheckPermissions(t *testing.T) {
 			givenPermissions:   []string{},
 			expected:           false,
 		},
+		{
+			name:               "deny overrides group grant",
+			requiredPermission: "article.delete",
+			givenPermissions:   []string{"article", "!article.delete"},
+			expected:           false,
+		},
+		{
+			name:               "deny does not affect sibling permissions",
+			requiredPermission: "article.update",
+			givenPermissions:   []string{"article", "!article.delete"},
+			expected:           true,
+		},
+		{
+			name:               "deny applies to children",
+			requiredPermission: "article.comment.delete",
+			givenPermissions:   []string{"*", "!article.comment"},
+			expected:           false,
+		},
+		{
+			name:               "deny overrides grant regardless of order",
+			requiredPermission: "article.delete",
+			givenPermissions:   []string{"!article.delete", "article.delete"},
+			expected:           false,
+		},
+		{
+			name:               "deny wildcard revokes everything",
+			requiredPermission: "article.read",
+			givenPermissions:   []string{"*", "!*"},
+			expected:           false,
+		},
+		{
+			name:               "deny alone grants nothing",
+			requiredPermission: "article.read",
+			givenPermissions:   []string{"!article.delete"},
+			expected:           false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -211,3 +508,126 @@ func TestManager_CheckRolesPermission(t *testing.T) {
 		})
 	}
 }
+
+func TestManager_CheckRolesPermissionDeny(t *testing.T) {
+	m := setupTestManager(t)
+
+	_, err := m.CreateRole("editor", RoleConfig{
+		Name:        "Editor",
+		Permissions: []string{"article", "!article.delete"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create editor role: %v", err)
+	}
+
+	_, err = m.CreateRole("admin", RoleConfig{
+		Name:        "Admin",
+		Permissions: []string{"*"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create admin role: %v", err)
+	}
+
+	_, err = m.CreateRole("junior-editor", RoleConfig{
+		Name:        "Junior Editor",
+		Parents:     []string{"editor"},
+		Permissions: []string{"article.delete"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create junior-editor role: %v", err)
+	}
+
+	tests := []struct {
+		name               string
+		roleKeys           []string
+		requiredPermission string
+		expected           bool
+	}{
+		{
+			name:               "deny within the role",
+			roleKeys:           []string{"editor"},
+			requiredPermission: "article.delete",
+			expected:           false,
+		},
+		{
+			name:               "grant next to deny",
+			roleKeys:           []string{"editor"},
+			requiredPermission: "article.update",
+			expected:           true,
+		},
+		{
+			name:               "deny overrides grant from another role",
+			roleKeys:           []string{"admin", "editor"},
+			requiredPermission: "article.delete",
+			expected:           false,
+		},
+		{
+			name:               "inherited deny overrides own grant",
+			roleKeys:           []string{"junior-editor"},
+			requiredPermission: "article.delete",
+			expected:           false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := m.CheckRolesPermission(tt.roleKeys, tt.requiredPermission)
+			if err != nil {
+				t.Fatalf("failed to check roles permission: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("CheckRolesPermission(%v, %q) = %v, want %v",
+					tt.roleKeys, tt.requiredPermission, result, tt.expected)
+			}
+		})
+	}
+
+	hasPermission, err := m.CheckRolePermission("editor", "article.delete")
+	if err != nil {
+		t.Fatalf("failed to check permission: %v", err)
+	}
+	if hasPermission {
+		t.Error("expected editor not to have 'article.delete' permission")
+	}
+}
+
+func TestManager_WithMatchMode(t *testing.T) {
+	legacy := setupTestManager(t)
+	strict := setupTestManager(t, WithMatchMode(MatchStrict))
+
+	for _, m := range []*Manager{legacy, strict} {
+		_, err := m.CreateRole("operator", RoleConfig{
+			Name:        "Operator",
+			Permissions: []string{"infrastructure.vm.start"},
+		})
+		if err != nil {
+			t.Fatalf("failed to create role: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name               string
+		manager            *Manager
+		requiredPermission string
+		expected           bool
+	}{
+		{"legacy exact match", legacy, "infrastructure.vm.start", true},
+		{"legacy child match", legacy, "infrastructure", true},
+		{"strict exact match", strict, "infrastructure.vm.start", true},
+		{"strict child match", strict, "infrastructure", false},
+		{"strict child match of direct parent", strict, "infrastructure.vm", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.manager.CheckRolesPermission([]string{"operator"}, tt.requiredPermission)
+			if err != nil {
+				t.Fatalf("failed to check roles permission: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("CheckRolesPermission(%q) = %v, want %v",
+					tt.requiredPermission, result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,408 @@
+package privy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrInvalidPolicy     = errors.New("invalid policy document")
+	ErrUnsupportedFormat = errors.New("unsupported policy format")
+)
+
+// PolicyVersion is the version of the policy document schema written by Export
+const PolicyVersion = 1
+
+// Format is the encoding of a policy document
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+)
+
+// ImportMode controls how Import treats data that is not part of the document
+type ImportMode int
+
+const (
+	// ImportMerge creates or updates the resources, actions and roles of the document and
+	// keeps everything else in storage
+	ImportMerge ImportMode = iota
+
+	// ImportReplace makes storage match the document: resources, actions and roles that are
+	// not part of it are deleted, together with the subject bindings of deleted roles
+	ImportReplace
+)
+
+// PolicyDocument is the serialized form of the resource catalog and roles.
+//
+// In YAML:
+//
+//	version: 1
+//	resources:
+//	  - key: article
+//	    name: Article
+//	    actions:
+//	      - key: read
+//	        name: Read
+//	    resources:
+//	      - key: comment
+//	        actions:
+//	          - key: create
+//	roles:
+//	  - key: editor
+//	    name: Editor
+//	    permissions: [article.read, article.comment]
+//	    parents: [viewer]
+//
+// Roles listed in a document are authoritative: Import sets their name, description,
+// permissions and parents to the values in the document.
+type PolicyDocument struct {
+	Version   int              `json:"version" yaml:"version"`
+	Resources []PolicyResource `json:"resources,omitempty" yaml:"resources,omitempty"`
+	Roles     []PolicyRole     `json:"roles,omitempty" yaml:"roles,omitempty"`
+}
+
+// PolicyResource is a resource with its actions and sub-resources in a policy document
+type PolicyResource struct {
+	Key         string           `json:"key" yaml:"key"`
+	Name        string           `json:"name,omitempty" yaml:"name,omitempty"`
+	Description string           `json:"description,omitempty" yaml:"description,omitempty"`
+	Actions     []PolicyAction   `json:"actions,omitempty" yaml:"actions,omitempty"`
+	Resources   []PolicyResource `json:"resources,omitempty" yaml:"resources,omitempty"`
+}
+
+// PolicyAction is an action of a resource in a policy document
+type PolicyAction struct {
+	Key         string `json:"key" yaml:"key"`
+	Name        string `json:"name,omitempty" yaml:"name,omitempty"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// PolicyRole is a role in a policy document
+type PolicyRole struct {
+	Key         string   `json:"key" yaml:"key"`
+	Name        string   `json:"name,omitempty" yaml:"name,omitempty"`
+	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+	Permissions []string `json:"permissions,omitempty" yaml:"permissions,omitempty"`
+	Parents     []string `json:"parents,omitempty" yaml:"parents,omitempty"`
+}
+
+// ImportOptions configures Import
+type ImportOptions struct {
+	// Format of the document; FormatYAML also accepts JSON documents
+	Format Format
+	Mode   ImportMode
+}
+
+// Export writes the full resource tree with its actions and all roles as a policy document.
+// It uses context.Background internally; to specify the context, use ExportContext.
+func (m *Manager) Export(w io.Writer, format Format) error {
+	return m.ExportContext(context.Background(), w, format)
+}
+
+// ExportContext is like Export but runs storage operations with the given context
+func (m *Manager) ExportContext(ctx context.Context, w io.Writer, format Format) error {
+	if format != FormatJSON && format != FormatYAML {
+		return fmt.Errorf("%w %q", ErrUnsupportedFormat, format)
+	}
+
+	var doc *PolicyDocument
+	err := m.storage.WithContext(ctx).WithTx(func(s Storage) error {
+		var err error
+		doc, err = m.exportPolicy(s)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if format == FormatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(doc)
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// exportPolicy reads the resource tree and roles into a policy document
+func (m *Manager) exportPolicy(s Storage) (*PolicyDocument, error) {
+	resources, err := exportResources(s, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := s.ListRoles()
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &PolicyDocument{
+		Version:   PolicyVersion,
+		Resources: resources,
+		Roles:     make([]PolicyRole, 0, len(roles)),
+	}
+
+	for _, role := range roles {
+		doc.Roles = append(doc.Roles, exportRole(role))
+	}
+
+	return doc, nil
+}
+
+// exportRole converts a role into its policy document form
+func exportRole(role Role) PolicyRole {
+	return PolicyRole{
+		Key:         role.Key,
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: role.Permissions,
+		Parents:     role.Parents,
+	}
+}
+
+// exportResources reads the resources under a parent, and recursively their sub-resources
+func exportResources(s Storage, parentID *uint) ([]PolicyResource, error) {
+	resources, err := s.ListResources(parentID)
+	if err != nil {
+		return nil, err
+	}
+
+	exported := make([]PolicyResource, 0, len(resources))
+	for _, resource := range resources {
+		r, err := exportResource(s, resource)
+		if err != nil {
+			return nil, err
+		}
+		exported = append(exported, *r)
+	}
+
+	return exported, nil
+}
+
+// exportResource reads a resource with its actions and sub-resources
+func exportResource(s Storage, resource Resource) (*PolicyResource, error) {
+	r := &PolicyResource{
+		Key:         resource.Key,
+		Name:        resource.Name,
+		Description: resource.Description,
+	}
+
+	for _, action := range resource.Actions {
+		r.Actions = append(r.Actions, PolicyAction{
+			Key:         action.Key,
+			Name:        action.Name,
+			Description: action.Description,
+		})
+	}
+
+	var err error
+	if r.Resources, err = exportResources(s, &resource.ID); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Import reads a policy document and applies it in a single transaction. The document is
+// decoded and validated before storage is touched; unknown fields, unsupported versions,
+// invalid keys and permissions and duplicate entries are rejected with ErrInvalidPolicy.
+// It uses context.Background internally; to specify the context, use ImportContext.
+func (m *Manager) Import(r io.Reader, opts ImportOptions) error {
+	return m.ImportContext(context.Background(), r, opts)
+}
+
+// ImportContext is like Import but runs storage operations with the given context
+func (m *Manager) ImportContext(ctx context.Context, r io.Reader, opts ImportOptions) error {
+	doc, err := decodePolicy(r, opts.Format)
+	if err != nil {
+		return err
+	}
+
+	if err := validatePolicy(doc); err != nil {
+		return err
+	}
+
+	c := change{
+		operation:  AuditImport,
+		targetType: AuditTargetPolicy,
+		invalidate: true,
+		snapshot: func(s Storage) (any, error) {
+			return m.exportPolicy(s)
+		},
+	}
+
+	return m.write(ctx, c, func(s Storage) error {
+		return m.importPolicy(s, doc, opts.Mode)
+	})
+}
+
+// decodePolicy decodes a policy document, rejecting unknown fields
+func decodePolicy(r io.Reader, format Format) (*PolicyDocument, error) {
+	var doc PolicyDocument
+
+	switch format {
+	case FormatJSON:
+		dec := json.NewDecoder(r)
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPolicy, err)
+		}
+	case FormatYAML:
+		dec := yaml.NewDecoder(r)
+		dec.KnownFields(true)
+		if err := dec.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPolicy, err)
+		}
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnsupportedFormat, format)
+	}
+
+	return &doc, nil
+}
+
+// validatePolicy checks a policy document without accessing storage
+func validatePolicy(doc *PolicyDocument) error {
+	if doc.Version != PolicyVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidPolicy, doc.Version)
+	}
+
+	if err := validateSubResources("", policyResourceTree(doc.Resources)); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPolicy, err)
+	}
+
+	keys := make(map[string]bool, len(doc.Roles))
+	for _, role := range doc.Roles {
+		if role.Key == "" {
+			return fmt.Errorf("%w: role with empty key", ErrInvalidPolicy)
+		}
+		if keys[role.Key] {
+			return fmt.Errorf("%w: role %q declared twice", ErrInvalidPolicy, role.Key)
+		}
+		keys[role.Key] = true
+
+		if err := validatePermissions(role.Permissions); err != nil {
+			return fmt.Errorf("%w: role %q: %v", ErrInvalidPolicy, role.Key, err)
+		}
+
+		for _, parent := range role.Parents {
+			if parent == role.Key {
+				return fmt.Errorf("%w: role %q inherits from itself", ErrInvalidPolicy, role.Key)
+			}
+		}
+	}
+
+	return nil
+}
+
+// policyResourceTree converts policy resources into resource configurations
+func policyResourceTree(resources []PolicyResource) []Resource {
+	tree := make([]Resource, 0, len(resources))
+	for _, r := range resources {
+		resource := Resource{
+			Key:          r.Key,
+			Name:         r.Name,
+			Description:  r.Description,
+			SubResources: policyResourceTree(r.Resources),
+		}
+
+		for _, a := range r.Actions {
+			resource.Actions = append(resource.Actions, DefineAction(a.Key, a.Name, a.Description))
+		}
+
+		tree = append(tree, resource)
+	}
+	return tree
+}
+
+// importPolicy applies a validated policy document
+func (m *Manager) importPolicy(s Storage, doc *PolicyDocument, mode ImportMode) error {
+	report := &SyncReport{}
+	opts := SyncOptions{Prune: mode == ImportReplace}
+	if err := m.syncResources(s, nil, "", policyResourceTree(doc.Resources), opts, report); err != nil {
+		return err
+	}
+
+	declared := make(map[string]bool, len(doc.Roles))
+	for _, role := range doc.Roles {
+		declared[role.Key] = true
+	}
+
+	if mode == ImportReplace {
+		roles, err := s.ListRoles()
+		if err != nil {
+			return err
+		}
+		for _, role := range roles {
+			if !declared[role.Key] {
+				if err := s.DeleteRole(role.ID); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	// Store the roles without parents first, so that parents can refer to any role of the
+	// document and cycles are only checked against the inheritance the document declares
+	for _, config := range doc.Roles {
+		if err := m.validateRegisteredPermissions(s, config.Permissions); err != nil {
+			return err
+		}
+
+		role, err := s.GetRole(config.Key)
+		if err != nil && err != ErrRoleNotFound {
+			return err
+		}
+
+		if role == nil {
+			err = s.CreateRole(&Role{
+				Key:         config.Key,
+				Name:        config.Name,
+				Description: config.Description,
+				Permissions: config.Permissions,
+			})
+		} else {
+			role.Name = config.Name
+			role.Description = config.Description
+			role.Permissions = config.Permissions
+			role.Parents = nil
+			err = s.UpdateRole(role)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, config := range doc.Roles {
+		if len(config.Parents) == 0 {
+			continue
+		}
+
+		if err := m.checkRoleCycle(s, config.Key, config.Parents); err != nil {
+			return fmt.Errorf("role %q: %w", config.Key, err)
+		}
+
+		role, err := s.GetRole(config.Key)
+		if err != nil {
+			return err
+		}
+
+		role.Parents = config.Parents
+		if err := s.UpdateRole(role); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
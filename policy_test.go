@@ -0,0 +1,212 @@
+package privy
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func setupPolicyTestManager(t *testing.T) *Manager {
+	m := setupTestManager(t)
+
+	_, err := m.CreateResource(ResourceConfig{
+		Key:         "article",
+		Name:        "Article",
+		Description: "News article entity",
+		Actions: []Action{
+			DefineAction("read", "Read", "Read article content"),
+			DefineAction("update", "Update", "Edit existing article"),
+		},
+		SubResources: []Resource{
+			{
+				Key:     "comment",
+				Name:    "Comment",
+				Actions: []Action{DefineAction("create", "Create", "Create a comment")},
+				SubResources: []Resource{
+					{Key: "reaction", Actions: []Action{DefineAction("add", "Add", "Add a reaction")}},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create resource: %v", err)
+	}
+
+	if _, err := m.CreateRole("viewer", RoleConfig{Name: "Viewer", Permissions: []string{"article.read"}}); err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+	_, err = m.CreateRole("editor", RoleConfig{
+		Name:        "Editor",
+		Permissions: []string{"article.update", "article.comment", "!article.comment.reaction"},
+		Parents:     []string{"viewer"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+
+	return m
+}
+
+func TestManager_ExportImportRoundTrip(t *testing.T) {
+	for _, format := range []Format{FormatJSON, FormatYAML} {
+		t.Run(string(format), func(t *testing.T) {
+			m := setupPolicyTestManager(t)
+
+			var exported bytes.Buffer
+			if err := m.Export(&exported, format); err != nil {
+				t.Fatalf("failed to export: %v", err)
+			}
+
+			imported := CreateManager(WithStorage(NewMemoryStorage()))
+			if err := imported.Import(bytes.NewReader(exported.Bytes()), ImportOptions{Format: format}); err != nil {
+				t.Fatalf("failed to import: %v", err)
+			}
+
+			var reexported bytes.Buffer
+			if err := imported.Export(&reexported, format); err != nil {
+				t.Fatalf("failed to export: %v", err)
+			}
+			if exported.String() != reexported.String() {
+				t.Errorf("expected round trip to be lossless\nexported:\n%s\nre-exported:\n%s", exported.String(), reexported.String())
+			}
+
+			hasPermission, err := imported.CheckRolePermission("editor", "article.read")
+			if err != nil {
+				t.Fatalf("failed to check permission: %v", err)
+			}
+			if !hasPermission {
+				t.Error("expected editor to inherit 'article.read' permission")
+			}
+
+			if _, err := imported.GetResource("article.comment.reaction"); err != nil {
+				t.Errorf("failed to get nested resource: %v", err)
+			}
+		})
+	}
+}
+
+func TestManager_ImportModes(t *testing.T) {
+	doc := `
+version: 1
+resources:
+  - key: article
+    name: Article
+    description: News article entity
+    actions:
+      - key: read
+        name: Read
+        description: Read article content
+roles:
+  - key: editor
+    name: Editor
+    permissions: [article.read]
+`
+
+	t.Run("merge", func(t *testing.T) {
+		m := setupPolicyTestManager(t)
+
+		if err := m.Import(strings.NewReader(doc), ImportOptions{Format: FormatYAML}); err != nil {
+			t.Fatalf("failed to import: %v", err)
+		}
+
+		// Roles in the document are replaced as a whole
+		editor, err := m.GetRole("editor")
+		if err != nil {
+			t.Fatalf("failed to get role: %v", err)
+		}
+		if len(editor.Permissions) != 1 || len(editor.Parents) != 0 {
+			t.Errorf("expected editor to match the document, got %+v", editor)
+		}
+
+		// Everything else is kept
+		if _, err := m.GetRole("viewer"); err != nil {
+			t.Errorf("expected viewer to be kept: %v", err)
+		}
+		if _, err := m.GetResource("article.comment"); err != nil {
+			t.Errorf("expected 'article.comment' to be kept: %v", err)
+		}
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		m := setupPolicyTestManager(t)
+		if err := m.BindRole("alice", "editor"); err != nil {
+			t.Fatalf("failed to bind role: %v", err)
+		}
+
+		if err := m.Import(strings.NewReader(doc), ImportOptions{Format: FormatYAML, Mode: ImportReplace}); err != nil {
+			t.Fatalf("failed to import: %v", err)
+		}
+
+		if _, err := m.GetRole("viewer"); err != ErrRoleNotFound {
+			t.Errorf("expected ErrRoleNotFound, got %v", err)
+		}
+		if _, err := m.GetResource("article.comment"); err != ErrResourceNotFound {
+			t.Errorf("expected ErrResourceNotFound, got %v", err)
+		}
+
+		r, err := m.GetResource("article")
+		if err != nil {
+			t.Fatalf("failed to get resource: %v", err)
+		}
+		if len(r.Actions) != 1 {
+			t.Errorf("expected 1 action, got %d", len(r.Actions))
+		}
+
+		// Bindings of kept roles survive
+		can, err := m.Can("alice", "article.read")
+		if err != nil {
+			t.Fatalf("failed to check permission: %v", err)
+		}
+		if !can {
+			t.Error("expected alice to keep 'article.read' permission")
+		}
+	})
+}
+
+func TestManager_ImportInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		doc    string
+		err    error
+	}{
+		{"unknown field", FormatJSON, `{"version": 1, "rolez": []}`, ErrInvalidPolicy},
+		{"missing version", FormatYAML, "roles: []", ErrInvalidPolicy},
+		{"invalid permission", FormatYAML, "version: 1\nroles:\n  - key: editor\n    permissions: ['article..read']", ErrInvalidPolicy},
+		{"invalid resource key", FormatYAML, "version: 1\nresources:\n  - key: article.comment", ErrInvalidPolicy},
+		{"duplicate role", FormatYAML, "version: 1\nroles:\n  - key: editor\n  - key: editor", ErrInvalidPolicy},
+		{"unknown parent", FormatYAML, "version: 1\nroles:\n  - key: editor\n    parents: [admin]", ErrRoleNotFound},
+		{
+			"cycle",
+			FormatYAML,
+			"version: 1\nroles:\n  - key: viewer\n    parents: [editor]\n  - key: editor\n    parents: [viewer]",
+			ErrRoleCycle,
+		},
+		{"unsupported format", Format("toml"), "version = 1", ErrUnsupportedFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := setupPolicyTestManager(t)
+
+			var before bytes.Buffer
+			if err := m.Export(&before, FormatJSON); err != nil {
+				t.Fatalf("failed to export: %v", err)
+			}
+
+			err := m.Import(strings.NewReader(tt.doc), ImportOptions{Format: tt.format, Mode: ImportReplace})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected %v, got %v", tt.err, err)
+			}
+
+			var after bytes.Buffer
+			if err := m.Export(&after, FormatJSON); err != nil {
+				t.Fatalf("failed to export: %v", err)
+			}
+			if before.String() != after.String() {
+				t.Errorf("expected storage to be unchanged after failed import")
+			}
+		})
+	}
+}
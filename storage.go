@@ -1,8 +1,23 @@
 package privy
 
+import "context"
+
 // Storage defines the interface for persisting and retrieving RBAC data
 type Storage interface {
+	// WithContext returns a Storage that performs all operations with the given context,
+	// so deadlines, cancellation and tracing reach the underlying database
+	WithContext(ctx context.Context) Storage
+
+	// WithTx runs fn in a transaction. All operations performed through the Storage passed
+	// to fn are committed if fn returns nil and rolled back if it returns an error.
+	// The transactional Storage must not be used after fn returns.
+	WithTx(fn func(tx Storage) error) error
+
 	// Resource operations
+	// Implementations return ErrDuplicateKey for a key that is already used under the same
+	// parent, including top-level resources, and likewise for actions, roles and bindings.
+	// Writes referencing a missing parent resource, resource or role fail with
+	// ErrResourceNotFound or ErrRoleNotFound rather than leaving orphan records.
 	CreateResource(resource *Resource) error
 	GetResource(key string, parentID *uint) (*Resource, error)
 	GetResourceByID(id uint) (*Resource, error)
@@ -14,9 +29,12 @@ type Storage interface {
 	CreateActions(resourceID uint, actions []Action) error
 	GetAction(resourceID uint, key string) (*Action, error)
 	ListActions(resourceID uint) ([]Action, error)
+	UpdateAction(action *Action) error
 	DeleteAction(id uint) error
 
 	// Role operations
+	// Implementations persist Role.Parents as inheritance edges and return
+	// ErrRoleNotFound when a parent role key does not exist.
 	CreateRole(role *Role) error
 	GetRole(key string) (*Role, error)
 	GetRoleByID(id uint) (*Role, error)
@@ -24,6 +42,13 @@ type Storage interface {
 	UpdateRole(role *Role) error
 	DeleteRole(id uint) error
 
+	// Role binding operations
+	CreateRoleBinding(binding *RoleBinding) error
+	GetRoleBinding(subjectID string, roleID uint) (*RoleBinding, error)
+	ListSubjectRoleBindings(subjectID string) ([]RoleBinding, error)
+	ListRoleBindings(roleID uint) ([]RoleBinding, error)
+	DeleteRoleBinding(id uint) error
+
 	// Initialize creates necessary tables/schemas
 	Initialize() error
 }
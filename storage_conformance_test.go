@@ -0,0 +1,26 @@
+package privy_test
+
+import (
+	"testing"
+
+	"github.com/weedbox/privy"
+	"github.com/weedbox/privy/storagetest"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestGormStorage_Conformance(t *testing.T) {
+	storagetest.RunConformance(t, func() privy.Storage {
+		db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+		if err != nil {
+			t.Fatalf("failed to open database: %v", err)
+		}
+		return privy.NewGormStorage(db)
+	})
+}
+
+func TestMemoryStorage_Conformance(t *testing.T) {
+	storagetest.RunConformance(t, func() privy.Storage {
+		return privy.NewMemoryStorage()
+	})
+}
@@ -1,6 +1,7 @@
 package privy
 
 import (
+	"context"
 	"errors"
 
 	"gorm.io/gorm"
@@ -10,6 +11,7 @@ var (
 	ErrResourceNotFound = errors.New("resource not found")
 	ErrActionNotFound   = errors.New("action not found")
 	ErrRoleNotFound     = errors.New("role not found")
+	ErrBindingNotFound  = errors.New("role binding not found")
 	ErrDuplicateKey     = errors.New("duplicate key")
 )
 
@@ -23,15 +25,95 @@ func NewGormStorage(db *gorm.DB) *GormStorage {
 	return &GormStorage{db: db}
 }
 
+// WithContext returns a GormStorage running all queries with the given context
+func (s *GormStorage) WithContext(ctx context.Context) Storage {
+	return &GormStorage{db: s.db.WithContext(ctx)}
+}
+
+// WithTx runs fn in a database transaction
+func (s *GormStorage) WithTx(fn func(tx Storage) error) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&GormStorage{db: tx})
+	})
+}
+
 // Initialize creates necessary tables
 func (s *GormStorage) Initialize() error {
-	return s.db.AutoMigrate(&Resource{}, &Action{}, &Role{})
+	return s.db.AutoMigrate(&Resource{}, &Action{}, &Role{}, &RoleParent{}, &RoleBinding{})
+}
+
+// translateError maps unique constraint violations to ErrDuplicateKey, whether or not
+// gorm.Config.TranslateError is enabled
+func (s *GormStorage) translateError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if translator, ok := s.db.Dialector.(gorm.ErrorTranslator); ok {
+		err = translator.Translate(err)
+	}
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return ErrDuplicateKey
+	}
+
+	return err
+}
+
+// checkExists returns notFound unless a row of the model with the given ID exists. Foreign keys
+// may not be enforced, e.g. by SQLite, so references are checked before they are written.
+func checkExists(tx *gorm.DB, model any, id uint, notFound error) error {
+	var count int64
+	if err := tx.Model(model).Where("id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return notFound
+	}
+
+	return nil
+}
+
+// checkParentResource makes sure the parent of a resource exists
+func checkParentResource(tx *gorm.DB, resource *Resource) error {
+	if resource.ParentID == nil {
+		return nil
+	}
+	return checkExists(tx, &Resource{}, *resource.ParentID, ErrResourceNotFound)
+}
+
+// checkTopLevelKey rejects a top-level resource whose key is taken by another top-level
+// resource. The unique index cannot enforce this since NULL parent IDs never compare equal.
+func checkTopLevelKey(tx *gorm.DB, resource *Resource) error {
+	if resource.ParentID != nil {
+		return nil
+	}
+
+	var count int64
+	err := tx.Model(&Resource{}).
+		Where("key = ? AND parent_id IS NULL AND id <> ?", resource.Key, resource.ID).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrDuplicateKey
+	}
+
+	return nil
 }
 
 // Resource operations
 
 func (s *GormStorage) CreateResource(resource *Resource) error {
-	return s.db.Create(resource).Error
+	return s.translateError(s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkParentResource(tx, resource); err != nil {
+			return err
+		}
+		if err := checkTopLevelKey(tx, resource); err != nil {
+			return err
+		}
+		return tx.Create(resource).Error
+	}))
 }
 
 func (s *GormStorage) GetResource(key string, parentID *uint) (*Resource, error) {
@@ -87,11 +169,42 @@ func (s *GormStorage) ListResources(parentID *uint) ([]Resource, error) {
 }
 
 func (s *GormStorage) UpdateResource(resource *Resource) error {
-	return s.db.Save(resource).Error
+	return s.translateError(s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkParentResource(tx, resource); err != nil {
+			return err
+		}
+		if err := checkTopLevelKey(tx, resource); err != nil {
+			return err
+		}
+		return tx.Save(resource).Error
+	}))
 }
 
 func (s *GormStorage) DeleteResource(id uint) error {
-	return s.db.Delete(&Resource{}, id).Error
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return deleteResourceTree(tx, id)
+	})
+}
+
+// deleteResourceTree removes a resource with its actions and all of its sub-resources
+// explicitly since foreign keys may not be enforced
+func deleteResourceTree(tx *gorm.DB, id uint) error {
+	var childIDs []uint
+	if err := tx.Model(&Resource{}).Where("parent_id = ?", id).Pluck("id", &childIDs).Error; err != nil {
+		return err
+	}
+
+	for _, childID := range childIDs {
+		if err := deleteResourceTree(tx, childID); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Where("resource_id = ?", id).Delete(&Action{}).Error; err != nil {
+		return err
+	}
+
+	return tx.Delete(&Resource{}, id).Error
 }
 
 // Action operations
@@ -100,7 +213,12 @@ func (s *GormStorage) CreateActions(resourceID uint, actions []Action) error {
 	for i := range actions {
 		actions[i].ResourceID = resourceID
 	}
-	return s.db.Create(&actions).Error
+	return s.translateError(s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkExists(tx, &Resource{}, resourceID, ErrResourceNotFound); err != nil {
+			return err
+		}
+		return tx.Create(&actions).Error
+	}))
 }
 
 func (s *GormStorage) GetAction(resourceID uint, key string) (*Action, error) {
@@ -126,6 +244,15 @@ func (s *GormStorage) ListActions(resourceID uint) ([]Action, error) {
 	return actions, nil
 }
 
+func (s *GormStorage) UpdateAction(action *Action) error {
+	return s.translateError(s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkExists(tx, &Resource{}, action.ResourceID, ErrResourceNotFound); err != nil {
+			return err
+		}
+		return tx.Save(action).Error
+	}))
+}
+
 func (s *GormStorage) DeleteAction(id uint) error {
 	return s.db.Delete(&Action{}, id).Error
 }
@@ -133,7 +260,12 @@ func (s *GormStorage) DeleteAction(id uint) error {
 // Role operations
 
 func (s *GormStorage) CreateRole(role *Role) error {
-	return s.db.Create(role).Error
+	return s.translateError(s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(role).Error; err != nil {
+			return err
+		}
+		return saveRoleParents(tx, role)
+	}))
 }
 
 func (s *GormStorage) GetRole(key string) (*Role, error) {
@@ -146,7 +278,12 @@ func (s *GormStorage) GetRole(key string) (*Role, error) {
 		return nil, err
 	}
 
-	return &role, nil
+	roles := []Role{role}
+	if err := loadRoleParents(s.db, roles); err != nil {
+		return nil, err
+	}
+
+	return &roles[0], nil
 }
 
 func (s *GormStorage) GetRoleByID(id uint) (*Role, error) {
@@ -159,7 +296,12 @@ func (s *GormStorage) GetRoleByID(id uint) (*Role, error) {
 		return nil, err
 	}
 
-	return &role, nil
+	roles := []Role{role}
+	if err := loadRoleParents(s.db, roles); err != nil {
+		return nil, err
+	}
+
+	return &roles[0], nil
 }
 
 func (s *GormStorage) ListRoles() ([]Role, error) {
@@ -169,13 +311,156 @@ func (s *GormStorage) ListRoles() ([]Role, error) {
 		return nil, err
 	}
 
+	if err := loadRoleParents(s.db, roles); err != nil {
+		return nil, err
+	}
+
 	return roles, nil
 }
 
 func (s *GormStorage) UpdateRole(role *Role) error {
-	return s.db.Save(role).Error
+	return s.translateError(s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(role).Error; err != nil {
+			return err
+		}
+		return saveRoleParents(tx, role)
+	}))
 }
 
 func (s *GormStorage) DeleteRole(id uint) error {
-	return s.db.Delete(&Role{}, id).Error
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		// Remove bindings and inheritance edges explicitly since foreign keys may not be enforced
+		if err := tx.Where("role_id = ?", id).Delete(&RoleBinding{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("role_id = ? OR parent_id = ?", id, id).Delete(&RoleParent{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&Role{}, id).Error
+	})
+}
+
+// saveRoleParents replaces the inheritance edges of a role with the roles listed in role.Parents
+func saveRoleParents(tx *gorm.DB, role *Role) error {
+	if err := tx.Where("role_id = ?", role.ID).Delete(&RoleParent{}).Error; err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool)
+	for _, key := range role.Parents {
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		var parent Role
+		err := tx.Select("id").Where("key = ?", key).First(&parent).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrRoleNotFound
+			}
+			return err
+		}
+
+		if err := tx.Create(&RoleParent{RoleID: role.ID, ParentID: parent.ID}).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadRoleParents fills in the parent role keys of the given roles
+func loadRoleParents(db *gorm.DB, roles []Role) error {
+	if len(roles) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, 0, len(roles))
+	for _, role := range roles {
+		ids = append(ids, role.ID)
+	}
+
+	// Query through the models so that custom naming strategies are honored
+	var edges []RoleParent
+	if err := db.Model(&RoleParent{}).Where("role_id IN ?", ids).Order("id").Find(&edges).Error; err != nil {
+		return err
+	}
+	parentIDs := make([]uint, 0, len(edges))
+	for _, edge := range edges {
+		parentIDs = append(parentIDs, edge.ParentID)
+	}
+
+	var parentRoles []Role
+	if len(parentIDs) > 0 {
+		if err := db.Model(&Role{}).Select("id", "key").Where("id IN ?", parentIDs).Find(&parentRoles).Error; err != nil {
+			return err
+		}
+	}
+
+	keys := make(map[uint]string, len(parentRoles))
+	for _, parent := range parentRoles {
+		keys[parent.ID] = parent.Key
+	}
+
+	parents := make(map[uint][]string)
+	for _, edge := range edges {
+		if key, ok := keys[edge.ParentID]; ok {
+			parents[edge.RoleID] = append(parents[edge.RoleID], key)
+		}
+	}
+
+	for i := range roles {
+		roles[i].Parents = parents[roles[i].ID]
+	}
+
+	return nil
+}
+
+// Role binding operations
+
+func (s *GormStorage) CreateRoleBinding(binding *RoleBinding) error {
+	return s.translateError(s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkExists(tx, &Role{}, binding.RoleID, ErrRoleNotFound); err != nil {
+			return err
+		}
+		return tx.Create(binding).Error
+	}))
+}
+
+func (s *GormStorage) GetRoleBinding(subjectID string, roleID uint) (*RoleBinding, error) {
+	var binding RoleBinding
+	err := s.db.Where("subject_id = ? AND role_id = ?", subjectID, roleID).First(&binding).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBindingNotFound
+		}
+		return nil, err
+	}
+
+	return &binding, nil
+}
+
+func (s *GormStorage) ListSubjectRoleBindings(subjectID string) ([]RoleBinding, error) {
+	var bindings []RoleBinding
+	err := s.db.Where("subject_id = ?", subjectID).Order("id").Find(&bindings).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return bindings, nil
+}
+
+func (s *GormStorage) ListRoleBindings(roleID uint) ([]RoleBinding, error) {
+	var bindings []RoleBinding
+	err := s.db.Where("role_id = ?", roleID).Order("id").Find(&bindings).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return bindings, nil
+}
+
+func (s *GormStorage) DeleteRoleBinding(id uint) error {
+	return s.db.Delete(&RoleBinding{}, id).Error
 }
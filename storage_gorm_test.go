@@ -5,6 +5,7 @@ import (
 
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
 )
 
 func setupTestDB(t *testing.T) *GormStorage {
@@ -326,3 +327,197 @@ func TestGormStorage_DeleteRole(t *testing.T) {
 		t.Errorf("expected ErrRoleNotFound, got %v", err)
 	}
 }
+
+func TestGormStorage_RoleBindings(t *testing.T) {
+	storage := setupTestDB(t)
+
+	role := &Role{Key: "editor", Name: "Editor"}
+	if err := storage.CreateRole(role); err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+
+	binding := &RoleBinding{SubjectID: "alice", RoleID: role.ID}
+	if err := storage.CreateRoleBinding(binding); err != nil {
+		t.Fatalf("failed to create role binding: %v", err)
+	}
+
+	if binding.ID == 0 {
+		t.Error("expected binding ID to be set")
+	}
+
+	// Duplicate bindings must be rejected
+	if err := storage.CreateRoleBinding(&RoleBinding{SubjectID: "alice", RoleID: role.ID}); err == nil {
+		t.Error("expected error for duplicate role binding")
+	}
+
+	retrieved, err := storage.GetRoleBinding("alice", role.ID)
+	if err != nil {
+		t.Fatalf("failed to get role binding: %v", err)
+	}
+	if retrieved.ID != binding.ID {
+		t.Errorf("expected binding ID %d, got %d", binding.ID, retrieved.ID)
+	}
+
+	bindings, err := storage.ListSubjectRoleBindings("alice")
+	if err != nil {
+		t.Fatalf("failed to list subject role bindings: %v", err)
+	}
+	if len(bindings) != 1 {
+		t.Errorf("expected 1 binding, got %d", len(bindings))
+	}
+
+	bindings, err = storage.ListRoleBindings(role.ID)
+	if err != nil {
+		t.Fatalf("failed to list role bindings: %v", err)
+	}
+	if len(bindings) != 1 {
+		t.Errorf("expected 1 binding, got %d", len(bindings))
+	}
+
+	if err := storage.DeleteRoleBinding(binding.ID); err != nil {
+		t.Fatalf("failed to delete role binding: %v", err)
+	}
+
+	_, err = storage.GetRoleBinding("alice", role.ID)
+	if err != ErrBindingNotFound {
+		t.Errorf("expected ErrBindingNotFound, got %v", err)
+	}
+}
+
+func TestGormStorage_RoleParents(t *testing.T) {
+	storage := setupTestDB(t)
+
+	viewer := &Role{Key: "viewer", Name: "Viewer"}
+	if err := storage.CreateRole(viewer); err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+
+	editor := &Role{Key: "editor", Name: "Editor", Parents: []string{"viewer"}}
+	if err := storage.CreateRole(editor); err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+
+	retrieved, err := storage.GetRole("editor")
+	if err != nil {
+		t.Fatalf("failed to get role: %v", err)
+	}
+	if len(retrieved.Parents) != 1 || retrieved.Parents[0] != "viewer" {
+		t.Errorf("expected parents [viewer], got %v", retrieved.Parents)
+	}
+
+	// Unknown parent roles must be rejected
+	retrieved.Parents = append(retrieved.Parents, "nonexistent")
+	if err := storage.UpdateRole(retrieved); err != ErrRoleNotFound {
+		t.Errorf("expected ErrRoleNotFound, got %v", err)
+	}
+
+	// Deleting a parent role removes the inheritance edge
+	if err := storage.DeleteRole(viewer.ID); err != nil {
+		t.Fatalf("failed to delete role: %v", err)
+	}
+
+	roles, err := storage.ListRoles()
+	if err != nil {
+		t.Fatalf("failed to list roles: %v", err)
+	}
+	if len(roles) != 1 || len(roles[0].Parents) != 0 {
+		t.Errorf("expected editor without parents, got %v", roles)
+	}
+}
+
+func TestGormStorage_NamingStrategy(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{TablePrefix: "rbac_", SingularTable: true},
+	})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+
+	m := CreateManager(WithStorage(NewGormStorage(db)))
+
+	if _, err := m.CreateRole("viewer", RoleConfig{Permissions: []string{"article.read"}}); err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+	if _, err := m.CreateRole("editor", RoleConfig{Parents: []string{"viewer"}}); err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+
+	role, err := m.GetRole("editor")
+	if err != nil {
+		t.Fatalf("failed to get role: %v", err)
+	}
+	if len(role.Parents) != 1 || role.Parents[0] != "viewer" {
+		t.Errorf("expected parents [viewer], got %v", role.Parents)
+	}
+
+	hasPermission, err := m.CheckRolePermission("editor", "article.read")
+	if err != nil {
+		t.Fatalf("failed to check permission: %v", err)
+	}
+	if !hasPermission {
+		t.Error("expected editor to inherit 'article.read'")
+	}
+}
+
+func TestGormStorage_DuplicateKeys(t *testing.T) {
+	storage := setupTestDB(t)
+
+	if err := storage.CreateResource(&Resource{Key: "article"}); err != nil {
+		t.Fatalf("failed to create resource: %v", err)
+	}
+	if err := storage.CreateResource(&Resource{Key: "article"}); err != ErrDuplicateKey {
+		t.Errorf("expected ErrDuplicateKey for duplicate top-level resource, got %v", err)
+	}
+
+	user := &Resource{Key: "user"}
+	if err := storage.CreateResource(user); err != nil {
+		t.Fatalf("failed to create resource: %v", err)
+	}
+	user.Key = "article"
+	if err := storage.UpdateResource(user); err != ErrDuplicateKey {
+		t.Errorf("expected ErrDuplicateKey for renamed top-level resource, got %v", err)
+	}
+
+	if err := storage.CreateRole(&Role{Key: "viewer"}); err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+	if err := storage.CreateRole(&Role{Key: "viewer"}); err != ErrDuplicateKey {
+		t.Errorf("expected ErrDuplicateKey for duplicate role, got %v", err)
+	}
+}
+
+func TestGormStorage_MissingReferences(t *testing.T) {
+	storage := setupTestDB(t)
+
+	missing := uint(999)
+	if err := storage.CreateResource(&Resource{Key: "comment", ParentID: &missing}); err != ErrResourceNotFound {
+		t.Errorf("expected ErrResourceNotFound for missing parent, got %v", err)
+	}
+	if err := storage.CreateActions(missing, []Action{{Key: "read"}}); err != ErrResourceNotFound {
+		t.Errorf("expected ErrResourceNotFound for missing resource, got %v", err)
+	}
+	if err := storage.CreateRoleBinding(&RoleBinding{SubjectID: "alice", RoleID: missing}); err != ErrRoleNotFound {
+		t.Errorf("expected ErrRoleNotFound for missing role, got %v", err)
+	}
+
+	article := &Resource{Key: "article", Actions: []Action{{Key: "read"}}}
+	if err := storage.CreateResource(article); err != nil {
+		t.Fatalf("failed to create resource: %v", err)
+	}
+	article.ParentID = &missing
+	if err := storage.UpdateResource(article); err != ErrResourceNotFound {
+		t.Errorf("expected ErrResourceNotFound for moving to a missing parent, got %v", err)
+	}
+
+	action := article.Actions[0]
+	action.ResourceID = missing
+	if err := storage.UpdateAction(&action); err != ErrResourceNotFound {
+		t.Errorf("expected ErrResourceNotFound for moving an action to a missing resource, got %v", err)
+	}
+
+	var count int64
+	storage.db.Model(&Resource{}).Count(&count)
+	if count != 1 {
+		t.Errorf("expected no orphan resources, got %d resources", count)
+	}
+}
@@ -0,0 +1,831 @@
+package privy
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStorage implements Storage interface in memory.
+// It is safe for concurrent use and is mainly intended for tests and prototyping.
+type MemoryStorage struct {
+	*memoryState
+
+	ctx context.Context
+
+	// inTx is set on the views passed to WithTx callbacks, which already hold the write lock
+	inTx bool
+}
+
+// memoryState holds the data shared by a MemoryStorage and the views created by WithContext
+type memoryState struct {
+	mu sync.RWMutex
+
+	memoryData
+}
+
+// memoryData holds the records of a MemoryStorage
+type memoryData struct {
+	resources map[uint]*Resource
+	actions   map[uint]*Action
+	roles     map[uint]*Role
+	parents   map[uint][]uint
+	bindings  map[uint]*RoleBinding
+
+	nextResourceID uint
+	nextActionID   uint
+	nextRoleID     uint
+	nextBindingID  uint
+}
+
+// NewMemoryStorage creates a new MemoryStorage instance
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{
+		memoryState: &memoryState{
+			memoryData: memoryData{
+				resources: make(map[uint]*Resource),
+				actions:   make(map[uint]*Action),
+				roles:     make(map[uint]*Role),
+				parents:   make(map[uint][]uint),
+				bindings:  make(map[uint]*RoleBinding),
+			},
+		},
+		ctx: context.Background(),
+	}
+}
+
+// WithContext returns a MemoryStorage sharing the same data whose operations fail
+// once the given context is canceled or its deadline is exceeded
+func (s *MemoryStorage) WithContext(ctx context.Context) Storage {
+	return &MemoryStorage{memoryState: s.memoryState, ctx: ctx, inTx: s.inTx}
+}
+
+// WithTx runs fn while holding the write lock and restores the previous data
+// if fn returns an error or panics
+func (s *MemoryStorage) WithTx(fn func(tx Storage) error) error {
+	if !s.inTx {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	}
+
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+
+	snapshot := s.memoryData.clone()
+	committed := false
+	defer func() {
+		if !committed {
+			s.memoryData = snapshot
+		}
+	}()
+
+	if err := fn(&MemoryStorage{memoryState: s.memoryState, ctx: s.ctx, inTx: true}); err != nil {
+		return err
+	}
+
+	committed = true
+	return nil
+}
+
+// lock acquires the write lock unless the storage is used inside a transaction
+func (s *MemoryStorage) lock() func() {
+	if s.inTx {
+		return func() {}
+	}
+	s.mu.Lock()
+	return s.mu.Unlock
+}
+
+// rlock acquires the read lock unless the storage is used inside a transaction
+func (s *MemoryStorage) rlock() func() {
+	if s.inTx {
+		return func() {}
+	}
+	s.mu.RLock()
+	return s.mu.RUnlock
+}
+
+// Initialize is a no-op for in-memory storage
+func (s *MemoryStorage) Initialize() error {
+	return nil
+}
+
+// Resource operations
+
+func (s *MemoryStorage) CreateResource(resource *Resource) error {
+	defer s.lock()()
+
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := s.checkResourceTree(resource); err != nil {
+		return err
+	}
+
+	s.insertResourceTree(resource, time.Now())
+	return nil
+}
+
+// checkResourceTree makes sure a resource and its nested sub-resources and actions can be
+// inserted without violating unique keys
+func (s *MemoryStorage) checkResourceTree(resource *Resource) error {
+	if resource.ParentID != nil {
+		if _, ok := s.resources[*resource.ParentID]; !ok {
+			return ErrResourceNotFound
+		}
+	}
+
+	if s.findResource(resource.Key, resource.ParentID) != nil {
+		return ErrDuplicateKey
+	}
+
+	return checkNewResourceTree(resource)
+}
+
+// checkNewResourceTree checks the nested actions and sub-resources of a resource that does
+// not exist yet, so they can only collide with each other
+func checkNewResourceTree(resource *Resource) error {
+	if err := checkDuplicateActionKeys(resource.Actions); err != nil {
+		return err
+	}
+
+	keys := make(map[string]bool)
+	for i := range resource.SubResources {
+		sub := &resource.SubResources[i]
+		if keys[sub.Key] {
+			return ErrDuplicateKey
+		}
+		keys[sub.Key] = true
+
+		if err := checkNewResourceTree(sub); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// insertResourceTree stores a resource together with its nested actions and sub-resources
+func (s *MemoryStorage) insertResourceTree(resource *Resource, now time.Time) {
+	s.nextResourceID++
+	resource.ID = s.nextResourceID
+	resource.CreatedAt = now
+	resource.UpdatedAt = now
+
+	stored := *resource
+	stored.Actions = nil
+	stored.SubResources = nil
+	stored.ParentID = copyUintPtr(resource.ParentID)
+	s.resources[stored.ID] = &stored
+
+	for i := range resource.Actions {
+		resource.Actions[i].ResourceID = resource.ID
+		s.insertAction(&resource.Actions[i], now)
+	}
+
+	for i := range resource.SubResources {
+		resource.SubResources[i].ParentID = copyUintPtr(&resource.ID)
+		s.insertResourceTree(&resource.SubResources[i], now)
+	}
+}
+
+func (s *MemoryStorage) GetResource(key string, parentID *uint) (*Resource, error) {
+	defer s.rlock()()
+
+	if err := s.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	resource := s.findResource(key, parentID)
+	if resource == nil {
+		return nil, ErrResourceNotFound
+	}
+
+	return s.loadResource(resource), nil
+}
+
+func (s *MemoryStorage) GetResourceByID(id uint) (*Resource, error) {
+	defer s.rlock()()
+
+	if err := s.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	resource, ok := s.resources[id]
+	if !ok {
+		return nil, ErrResourceNotFound
+	}
+
+	return s.loadResource(resource), nil
+}
+
+func (s *MemoryStorage) ListResources(parentID *uint) ([]Resource, error) {
+	defer s.rlock()()
+
+	if err := s.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	resources := make([]Resource, 0)
+	for _, resource := range s.childResources(parentID) {
+		resources = append(resources, *s.loadResource(resource))
+	}
+
+	return resources, nil
+}
+
+func (s *MemoryStorage) UpdateResource(resource *Resource) error {
+	defer s.lock()()
+
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+
+	stored, ok := s.resources[resource.ID]
+	if !ok {
+		return ErrResourceNotFound
+	}
+
+	if resource.ParentID != nil {
+		if _, ok := s.resources[*resource.ParentID]; !ok {
+			return ErrResourceNotFound
+		}
+	}
+
+	if existing := s.findResource(resource.Key, resource.ParentID); existing != nil && existing.ID != resource.ID {
+		return ErrDuplicateKey
+	}
+
+	stored.Key = resource.Key
+	stored.Name = resource.Name
+	stored.Description = resource.Description
+	stored.ParentID = copyUintPtr(resource.ParentID)
+	stored.UpdatedAt = time.Now()
+	resource.UpdatedAt = stored.UpdatedAt
+
+	return nil
+}
+
+func (s *MemoryStorage) DeleteResource(id uint) error {
+	defer s.lock()()
+
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+
+	s.deleteResourceTree(id)
+	return nil
+}
+
+// deleteResourceTree removes a resource with its actions and all of its sub-resources
+func (s *MemoryStorage) deleteResourceTree(id uint) {
+	parentID := id
+	for _, child := range s.childResources(&parentID) {
+		s.deleteResourceTree(child.ID)
+	}
+
+	for actionID, action := range s.actions {
+		if action.ResourceID == id {
+			delete(s.actions, actionID)
+		}
+	}
+
+	delete(s.resources, id)
+}
+
+// findResource finds a stored resource by key under the given parent
+func (s *MemoryStorage) findResource(key string, parentID *uint) *Resource {
+	for _, resource := range s.resources {
+		if resource.Key == key && sameParent(resource.ParentID, parentID) {
+			return resource
+		}
+	}
+	return nil
+}
+
+// childResources lists stored resources under the given parent ordered by ID
+func (s *MemoryStorage) childResources(parentID *uint) []*Resource {
+	children := make([]*Resource, 0)
+	for _, resource := range s.resources {
+		if sameParent(resource.ParentID, parentID) {
+			children = append(children, resource)
+		}
+	}
+
+	sort.Slice(children, func(i, j int) bool {
+		return children[i].ID < children[j].ID
+	})
+
+	return children
+}
+
+// loadResource copies a stored resource with its actions and direct sub-resources,
+// mirroring the relations preloaded by GormStorage
+func (s *MemoryStorage) loadResource(resource *Resource) *Resource {
+	loaded := *resource
+	loaded.ParentID = copyUintPtr(resource.ParentID)
+	loaded.Actions = s.resourceActions(resource.ID)
+
+	loaded.SubResources = make([]Resource, 0)
+	id := resource.ID
+	for _, child := range s.childResources(&id) {
+		sub := *child
+		sub.ParentID = copyUintPtr(child.ParentID)
+		loaded.SubResources = append(loaded.SubResources, sub)
+	}
+
+	return &loaded
+}
+
+// Action operations
+
+func (s *MemoryStorage) CreateActions(resourceID uint, actions []Action) error {
+	defer s.lock()()
+
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+
+	if _, ok := s.resources[resourceID]; !ok {
+		return ErrResourceNotFound
+	}
+
+	if err := checkDuplicateActionKeys(actions); err != nil {
+		return err
+	}
+
+	for _, action := range actions {
+		if s.findAction(resourceID, action.Key) != nil {
+			return ErrDuplicateKey
+		}
+	}
+
+	now := time.Now()
+	for i := range actions {
+		actions[i].ResourceID = resourceID
+		s.insertAction(&actions[i], now)
+	}
+
+	return nil
+}
+
+// insertAction stores an action and assigns its ID
+func (s *MemoryStorage) insertAction(action *Action, now time.Time) {
+	s.nextActionID++
+	action.ID = s.nextActionID
+	action.CreatedAt = now
+	action.UpdatedAt = now
+
+	stored := *action
+	s.actions[stored.ID] = &stored
+}
+
+func (s *MemoryStorage) GetAction(resourceID uint, key string) (*Action, error) {
+	defer s.rlock()()
+
+	if err := s.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	action := s.findAction(resourceID, key)
+	if action == nil {
+		return nil, ErrActionNotFound
+	}
+
+	found := *action
+	return &found, nil
+}
+
+func (s *MemoryStorage) ListActions(resourceID uint) ([]Action, error) {
+	defer s.rlock()()
+
+	if err := s.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return s.resourceActions(resourceID), nil
+}
+
+func (s *MemoryStorage) UpdateAction(action *Action) error {
+	defer s.lock()()
+
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+
+	stored, ok := s.actions[action.ID]
+	if !ok {
+		return ErrActionNotFound
+	}
+
+	if _, ok := s.resources[action.ResourceID]; !ok {
+		return ErrResourceNotFound
+	}
+
+	if existing := s.findAction(action.ResourceID, action.Key); existing != nil && existing.ID != action.ID {
+		return ErrDuplicateKey
+	}
+
+	stored.Key = action.Key
+	stored.Name = action.Name
+	stored.Description = action.Description
+	stored.ResourceID = action.ResourceID
+	stored.UpdatedAt = time.Now()
+	action.UpdatedAt = stored.UpdatedAt
+
+	return nil
+}
+
+func (s *MemoryStorage) DeleteAction(id uint) error {
+	defer s.lock()()
+
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+
+	delete(s.actions, id)
+	return nil
+}
+
+// findAction finds a stored action by key on the given resource
+func (s *MemoryStorage) findAction(resourceID uint, key string) *Action {
+	for _, action := range s.actions {
+		if action.ResourceID == resourceID && action.Key == key {
+			return action
+		}
+	}
+	return nil
+}
+
+// resourceActions copies the actions of a resource ordered by ID
+func (s *MemoryStorage) resourceActions(resourceID uint) []Action {
+	actions := make([]Action, 0)
+	for _, action := range s.actions {
+		if action.ResourceID == resourceID {
+			actions = append(actions, *action)
+		}
+	}
+
+	sort.Slice(actions, func(i, j int) bool {
+		return actions[i].ID < actions[j].ID
+	})
+
+	return actions
+}
+
+// Role operations
+
+func (s *MemoryStorage) CreateRole(role *Role) error {
+	defer s.lock()()
+
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+
+	if s.findRole(role.Key) != nil {
+		return ErrDuplicateKey
+	}
+
+	parentIDs, err := s.resolveParentIDs(role.Parents)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	s.nextRoleID++
+	role.ID = s.nextRoleID
+	role.CreatedAt = now
+	role.UpdatedAt = now
+
+	s.roles[role.ID] = copyRole(role)
+	s.parents[role.ID] = parentIDs
+
+	return nil
+}
+
+func (s *MemoryStorage) GetRole(key string) (*Role, error) {
+	defer s.rlock()()
+
+	if err := s.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	role := s.findRole(key)
+	if role == nil {
+		return nil, ErrRoleNotFound
+	}
+
+	return s.loadRole(role), nil
+}
+
+func (s *MemoryStorage) GetRoleByID(id uint) (*Role, error) {
+	defer s.rlock()()
+
+	if err := s.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	role, ok := s.roles[id]
+	if !ok {
+		return nil, ErrRoleNotFound
+	}
+
+	return s.loadRole(role), nil
+}
+
+func (s *MemoryStorage) ListRoles() ([]Role, error) {
+	defer s.rlock()()
+
+	if err := s.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	roles := make([]Role, 0, len(s.roles))
+	for _, role := range s.roles {
+		roles = append(roles, *s.loadRole(role))
+	}
+
+	sort.Slice(roles, func(i, j int) bool {
+		return roles[i].ID < roles[j].ID
+	})
+
+	return roles, nil
+}
+
+func (s *MemoryStorage) UpdateRole(role *Role) error {
+	defer s.lock()()
+
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+
+	stored, ok := s.roles[role.ID]
+	if !ok {
+		return ErrRoleNotFound
+	}
+
+	if existing := s.findRole(role.Key); existing != nil && existing.ID != role.ID {
+		return ErrDuplicateKey
+	}
+
+	parentIDs, err := s.resolveParentIDs(role.Parents)
+	if err != nil {
+		return err
+	}
+
+	role.CreatedAt = stored.CreatedAt
+	role.UpdatedAt = time.Now()
+
+	s.roles[role.ID] = copyRole(role)
+	s.parents[role.ID] = parentIDs
+
+	return nil
+}
+
+func (s *MemoryStorage) DeleteRole(id uint) error {
+	defer s.lock()()
+
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+
+	// Remove bindings and inheritance edges referencing the role
+	for bindingID, binding := range s.bindings {
+		if binding.RoleID == id {
+			delete(s.bindings, bindingID)
+		}
+	}
+
+	delete(s.parents, id)
+	for roleID, parentIDs := range s.parents {
+		s.parents[roleID] = removeUint(parentIDs, id)
+	}
+
+	delete(s.roles, id)
+	return nil
+}
+
+// findRole finds a stored role by key
+func (s *MemoryStorage) findRole(key string) *Role {
+	for _, role := range s.roles {
+		if role.Key == key {
+			return role
+		}
+	}
+	return nil
+}
+
+// loadRole copies a stored role and fills in its parent role keys
+func (s *MemoryStorage) loadRole(role *Role) *Role {
+	loaded := copyRole(role)
+	loaded.Parents = nil
+	for _, parentID := range s.parents[role.ID] {
+		if parent, ok := s.roles[parentID]; ok {
+			loaded.Parents = append(loaded.Parents, parent.Key)
+		}
+	}
+	return loaded
+}
+
+// resolveParentIDs maps parent role keys to IDs, ignoring duplicates
+func (s *MemoryStorage) resolveParentIDs(keys []string) ([]uint, error) {
+	seen := make(map[string]bool)
+	ids := make([]uint, 0, len(keys))
+	for _, key := range keys {
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		parent := s.findRole(key)
+		if parent == nil {
+			return nil, ErrRoleNotFound
+		}
+		ids = append(ids, parent.ID)
+	}
+	return ids, nil
+}
+
+// Role binding operations
+
+func (s *MemoryStorage) CreateRoleBinding(binding *RoleBinding) error {
+	defer s.lock()()
+
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+
+	if _, ok := s.roles[binding.RoleID]; !ok {
+		return ErrRoleNotFound
+	}
+
+	if s.findBinding(binding.SubjectID, binding.RoleID) != nil {
+		return ErrDuplicateKey
+	}
+
+	s.nextBindingID++
+	binding.ID = s.nextBindingID
+	binding.CreatedAt = time.Now()
+
+	stored := *binding
+	s.bindings[stored.ID] = &stored
+
+	return nil
+}
+
+func (s *MemoryStorage) GetRoleBinding(subjectID string, roleID uint) (*RoleBinding, error) {
+	defer s.rlock()()
+
+	if err := s.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	binding := s.findBinding(subjectID, roleID)
+	if binding == nil {
+		return nil, ErrBindingNotFound
+	}
+
+	found := *binding
+	return &found, nil
+}
+
+func (s *MemoryStorage) ListSubjectRoleBindings(subjectID string) ([]RoleBinding, error) {
+	defer s.rlock()()
+
+	if err := s.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return s.listBindings(func(b *RoleBinding) bool { return b.SubjectID == subjectID }), nil
+}
+
+func (s *MemoryStorage) ListRoleBindings(roleID uint) ([]RoleBinding, error) {
+	defer s.rlock()()
+
+	if err := s.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return s.listBindings(func(b *RoleBinding) bool { return b.RoleID == roleID }), nil
+}
+
+func (s *MemoryStorage) DeleteRoleBinding(id uint) error {
+	defer s.lock()()
+
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+
+	delete(s.bindings, id)
+	return nil
+}
+
+// findBinding finds a stored binding of a role to a subject
+func (s *MemoryStorage) findBinding(subjectID string, roleID uint) *RoleBinding {
+	for _, binding := range s.bindings {
+		if binding.SubjectID == subjectID && binding.RoleID == roleID {
+			return binding
+		}
+	}
+	return nil
+}
+
+// listBindings copies the stored bindings matching a filter ordered by ID
+func (s *MemoryStorage) listBindings(match func(*RoleBinding) bool) []RoleBinding {
+	bindings := make([]RoleBinding, 0)
+	for _, binding := range s.bindings {
+		if match(binding) {
+			bindings = append(bindings, *binding)
+		}
+	}
+
+	sort.Slice(bindings, func(i, j int) bool {
+		return bindings[i].ID < bindings[j].ID
+	})
+
+	return bindings
+}
+
+// clone deep copies the records so they can be restored when a transaction fails
+func (d *memoryData) clone() memoryData {
+	c := *d
+
+	c.resources = make(map[uint]*Resource, len(d.resources))
+	for id, resource := range d.resources {
+		copied := *resource
+		copied.ParentID = copyUintPtr(resource.ParentID)
+		c.resources[id] = &copied
+	}
+
+	c.actions = make(map[uint]*Action, len(d.actions))
+	for id, action := range d.actions {
+		copied := *action
+		c.actions[id] = &copied
+	}
+
+	c.roles = make(map[uint]*Role, len(d.roles))
+	for id, role := range d.roles {
+		c.roles[id] = copyRole(role)
+	}
+
+	c.parents = make(map[uint][]uint, len(d.parents))
+	for id, parentIDs := range d.parents {
+		c.parents[id] = append([]uint(nil), parentIDs...)
+	}
+
+	c.bindings = make(map[uint]*RoleBinding, len(d.bindings))
+	for id, binding := range d.bindings {
+		copied := *binding
+		c.bindings[id] = &copied
+	}
+
+	return c
+}
+
+// checkDuplicateActionKeys rejects action lists that contain the same key twice
+func checkDuplicateActionKeys(actions []Action) error {
+	keys := make(map[string]bool)
+	for _, action := range actions {
+		if keys[action.Key] {
+			return ErrDuplicateKey
+		}
+		keys[action.Key] = true
+	}
+	return nil
+}
+
+// sameParent compares two optional parent IDs
+func sameParent(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+func copyUintPtr(p *uint) *uint {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
+
+func copyRole(role *Role) *Role {
+	copied := *role
+	copied.Permissions = append([]string(nil), role.Permissions...)
+	copied.Parents = append([]string(nil), role.Parents...)
+	return &copied
+}
+
+func removeUint(values []uint, value uint) []uint {
+	result := values[:0]
+	for _, v := range values {
+		if v != value {
+			result = append(result, v)
+		}
+	}
+	return result
+}
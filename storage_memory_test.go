@@ -0,0 +1,110 @@
+package privy
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestMemoryStorage_Manager(t *testing.T) {
+	m := CreateManager(WithStorage(NewMemoryStorage()))
+
+	_, err := m.CreateResource(ResourceConfig{
+		Key:     "article",
+		Name:    "Article",
+		Actions: []Action{DefineAction("read", "Read", "Read article content")},
+	})
+	if err != nil {
+		t.Fatalf("failed to create resource: %v", err)
+	}
+
+	if _, err := m.CreateResource(ResourceConfig{Key: "article"}); err != ErrResourceExists {
+		t.Errorf("expected ErrResourceExists, got %v", err)
+	}
+
+	if _, err := m.CreateRole("editor", RoleConfig{Name: "Editor", Permissions: []string{"article"}}); err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+	if err := m.BindRole("alice", "editor"); err != nil {
+		t.Fatalf("failed to bind role: %v", err)
+	}
+
+	hasPermission, err := m.Can("alice", "article.read")
+	if err != nil {
+		t.Fatalf("failed to check permission: %v", err)
+	}
+	if !hasPermission {
+		t.Error("expected alice to have 'article.read' permission")
+	}
+}
+
+func TestMemoryStorage_Concurrency(t *testing.T) {
+	storage := NewMemoryStorage()
+
+	resource := &Resource{Key: "article"}
+	if err := storage.CreateResource(resource); err != nil {
+		t.Fatalf("failed to create resource: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			key := fmt.Sprintf("role-%d", i)
+			if err := storage.CreateRole(&Role{Key: key}); err != nil {
+				t.Errorf("failed to create role: %v", err)
+				return
+			}
+			if err := storage.CreateActions(resource.ID, []Action{{Key: key}}); err != nil {
+				t.Errorf("failed to create action: %v", err)
+			}
+			if _, err := storage.GetRole(key); err != nil {
+				t.Errorf("failed to get role: %v", err)
+			}
+			if _, err := storage.ListResources(nil); err != nil {
+				t.Errorf("failed to list resources: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	roles, err := storage.ListRoles()
+	if err != nil {
+		t.Fatalf("failed to list roles: %v", err)
+	}
+	if len(roles) != 20 {
+		t.Errorf("expected 20 roles, got %d", len(roles))
+	}
+
+	actions, err := storage.ListActions(resource.ID)
+	if err != nil {
+		t.Fatalf("failed to list actions: %v", err)
+	}
+	if len(actions) != 20 {
+		t.Errorf("expected 20 actions, got %d", len(actions))
+	}
+}
+
+func TestMemoryStorage_ReturnsCopies(t *testing.T) {
+	storage := NewMemoryStorage()
+
+	if err := storage.CreateRole(&Role{Key: "editor", Permissions: []string{"article.read"}}); err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+
+	role, err := storage.GetRole("editor")
+	if err != nil {
+		t.Fatalf("failed to get role: %v", err)
+	}
+	role.Permissions[0] = "*"
+
+	role, err = storage.GetRole("editor")
+	if err != nil {
+		t.Fatalf("failed to get role: %v", err)
+	}
+	if role.Permissions[0] != "article.read" {
+		t.Errorf("expected stored role to be unaffected by caller changes, got %v", role.Permissions)
+	}
+}
@@ -0,0 +1,566 @@
+// Package storagetest provides a conformance test suite for privy.Storage implementations.
+//
+// Custom backends can certify that they satisfy the contract privy.Manager relies on:
+//
+//	func TestMyStorage(t *testing.T) {
+//		storagetest.RunConformance(t, func() privy.Storage {
+//			return NewMyStorage(...)
+//		})
+//	}
+package storagetest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/weedbox/privy"
+)
+
+// RunConformance runs the behaviors privy.Manager relies on against a Storage implementation:
+// resource nesting, action uniqueness per resource, role CRUD including inheritance edges,
+// role bindings, not-found errors, references to missing records, cascade deletes and
+// transactions. The factory must return a new, empty storage for every call; RunConformance
+// initializes it before use. Not-found errors must be the exact sentinel errors exported by
+// privy, since Manager compares them directly, and unique key violations must be reported
+// as privy.ErrDuplicateKey.
+func RunConformance(t *testing.T, factory func() privy.Storage) {
+	newStorage := func(t *testing.T) privy.Storage {
+		t.Helper()
+
+		storage := factory()
+		if err := storage.Initialize(); err != nil {
+			t.Fatalf("failed to initialize storage: %v", err)
+		}
+		return storage
+	}
+
+	t.Run("ResourceNesting", func(t *testing.T) {
+		storage := newStorage(t)
+
+		parent := &privy.Resource{Key: "article", Name: "Article"}
+		if err := storage.CreateResource(parent); err != nil {
+			t.Fatalf("failed to create resource: %v", err)
+		}
+		if parent.ID == 0 {
+			t.Fatal("expected resource ID to be set")
+		}
+
+		child := &privy.Resource{Key: "comment", Name: "Comment", ParentID: &parent.ID}
+		if err := storage.CreateResource(child); err != nil {
+			t.Fatalf("failed to create sub-resource: %v", err)
+		}
+
+		// The same key may be used under different parents
+		other := &privy.Resource{Key: "comment", Name: "Comment"}
+		if err := storage.CreateResource(other); err != nil {
+			t.Fatalf("failed to create top-level resource with reused key: %v", err)
+		}
+
+		// But not twice under the same parent
+		if err := storage.CreateResource(&privy.Resource{Key: "comment", ParentID: &parent.ID}); !errors.Is(err, privy.ErrDuplicateKey) {
+			t.Errorf("expected ErrDuplicateKey for duplicate resource key under the same parent, got %v", err)
+		}
+
+		// Nor twice at the top level
+		if err := storage.CreateResource(&privy.Resource{Key: "article"}); !errors.Is(err, privy.ErrDuplicateKey) {
+			t.Errorf("expected ErrDuplicateKey for duplicate top-level resource key, got %v", err)
+		}
+
+		retrieved, err := storage.GetResource("comment", &parent.ID)
+		if err != nil {
+			t.Fatalf("failed to get sub-resource: %v", err)
+		}
+		if retrieved.ID != child.ID || retrieved.ParentID == nil || *retrieved.ParentID != parent.ID {
+			t.Errorf("expected sub-resource %d under %d, got %+v", child.ID, parent.ID, retrieved)
+		}
+
+		retrieved, err = storage.GetResource("comment", nil)
+		if err != nil {
+			t.Fatalf("failed to get top-level resource: %v", err)
+		}
+		if retrieved.ID != other.ID {
+			t.Errorf("expected top-level resource %d, got %d", other.ID, retrieved.ID)
+		}
+
+		retrieved, err = storage.GetResourceByID(parent.ID)
+		if err != nil {
+			t.Fatalf("failed to get resource by ID: %v", err)
+		}
+		if len(retrieved.SubResources) != 1 || retrieved.SubResources[0].Key != "comment" {
+			t.Errorf("expected sub-resource 'comment' to be loaded, got %v", retrieved.SubResources)
+		}
+
+		top, err := storage.ListResources(nil)
+		if err != nil {
+			t.Fatalf("failed to list resources: %v", err)
+		}
+		if len(top) != 2 {
+			t.Errorf("expected 2 top-level resources, got %d", len(top))
+		}
+
+		children, err := storage.ListResources(&parent.ID)
+		if err != nil {
+			t.Fatalf("failed to list sub-resources: %v", err)
+		}
+		if len(children) != 1 || children[0].ID != child.ID {
+			t.Errorf("expected sub-resource %d, got %v", child.ID, children)
+		}
+
+		parent.Name = "News Article"
+		parent.Description = "News article entity"
+		if err := storage.UpdateResource(parent); err != nil {
+			t.Fatalf("failed to update resource: %v", err)
+		}
+
+		retrieved, err = storage.GetResource("article", nil)
+		if err != nil {
+			t.Fatalf("failed to get resource: %v", err)
+		}
+		if retrieved.Name != "News Article" || retrieved.Description != "News article entity" {
+			t.Errorf("expected updated resource, got %+v", retrieved)
+		}
+	})
+
+	t.Run("NestedCreate", func(t *testing.T) {
+		storage := newStorage(t)
+
+		resource := &privy.Resource{
+			Key:     "article",
+			Actions: []privy.Action{{Key: "read"}},
+			SubResources: []privy.Resource{
+				{
+					Key:          "comment",
+					Actions:      []privy.Action{{Key: "delete"}},
+					SubResources: []privy.Resource{{Key: "tag"}},
+				},
+			},
+		}
+		if err := storage.CreateResource(resource); err != nil {
+			t.Fatalf("failed to create resource tree: %v", err)
+		}
+
+		comment, err := storage.GetResource("comment", &resource.ID)
+		if err != nil {
+			t.Fatalf("failed to get sub-resource: %v", err)
+		}
+		if len(comment.Actions) != 1 || comment.Actions[0].Key != "delete" {
+			t.Errorf("expected sub-resource action 'delete', got %v", comment.Actions)
+		}
+
+		if _, err := storage.GetResource("tag", &comment.ID); err != nil {
+			t.Errorf("failed to get nested sub-resource: %v", err)
+		}
+	})
+
+	t.Run("ActionUniquenessPerResource", func(t *testing.T) {
+		storage := newStorage(t)
+
+		article := &privy.Resource{Key: "article"}
+		user := &privy.Resource{Key: "user"}
+		for _, r := range []*privy.Resource{article, user} {
+			if err := storage.CreateResource(r); err != nil {
+				t.Fatalf("failed to create resource: %v", err)
+			}
+		}
+
+		actions := []privy.Action{{Key: "read", Name: "Read"}, {Key: "update", Name: "Update"}}
+		if err := storage.CreateActions(article.ID, actions); err != nil {
+			t.Fatalf("failed to create actions: %v", err)
+		}
+		for _, a := range actions {
+			if a.ID == 0 || a.ResourceID != article.ID {
+				t.Errorf("expected action ID and resource ID to be set, got %+v", a)
+			}
+		}
+
+		// The same action key may be used on another resource
+		if err := storage.CreateActions(user.ID, []privy.Action{{Key: "read"}}); err != nil {
+			t.Fatalf("failed to create action with reused key: %v", err)
+		}
+
+		if err := storage.CreateActions(article.ID, []privy.Action{{Key: "read"}}); !errors.Is(err, privy.ErrDuplicateKey) {
+			t.Errorf("expected ErrDuplicateKey for duplicate action key, got %v", err)
+		}
+
+		if err := storage.CreateActions(article.ID, []privy.Action{{Key: "share"}, {Key: "share"}}); !errors.Is(err, privy.ErrDuplicateKey) {
+			t.Errorf("expected ErrDuplicateKey for duplicate action keys in one batch, got %v", err)
+		}
+
+		list, err := storage.ListActions(article.ID)
+		if err != nil {
+			t.Fatalf("failed to list actions: %v", err)
+		}
+		if len(list) != 2 {
+			t.Errorf("expected failed batches to leave 2 actions, got %d", len(list))
+		}
+
+		action, err := storage.GetAction(article.ID, "update")
+		if err != nil {
+			t.Fatalf("failed to get action: %v", err)
+		}
+		if action.Name != "Update" {
+			t.Errorf("expected action name 'Update', got '%s'", action.Name)
+		}
+
+		renamed := *action
+		renamed.Key = "read"
+		if err := storage.UpdateAction(&renamed); !errors.Is(err, privy.ErrDuplicateKey) {
+			t.Errorf("expected ErrDuplicateKey for action renamed to an existing key, got %v", err)
+		}
+
+		action.Name = "Edit"
+		action.Description = "Edit an article"
+		if err := storage.UpdateAction(action); err != nil {
+			t.Fatalf("failed to update action: %v", err)
+		}
+
+		action, err = storage.GetAction(article.ID, "update")
+		if err != nil {
+			t.Fatalf("failed to get action: %v", err)
+		}
+		if action.Name != "Edit" || action.Description != "Edit an article" {
+			t.Errorf("expected updated action, got %+v", action)
+		}
+
+		if err := storage.DeleteAction(action.ID); err != nil {
+			t.Fatalf("failed to delete action: %v", err)
+		}
+		if _, err := storage.GetAction(article.ID, "update"); err != privy.ErrActionNotFound {
+			t.Errorf("expected ErrActionNotFound, got %v", err)
+		}
+	})
+
+	t.Run("RoleCRUD", func(t *testing.T) {
+		storage := newStorage(t)
+
+		viewer := &privy.Role{Key: "viewer", Name: "Viewer", Permissions: []string{"article.read"}}
+		if err := storage.CreateRole(viewer); err != nil {
+			t.Fatalf("failed to create role: %v", err)
+		}
+		if viewer.ID == 0 {
+			t.Fatal("expected role ID to be set")
+		}
+
+		if err := storage.CreateRole(&privy.Role{Key: "viewer"}); !errors.Is(err, privy.ErrDuplicateKey) {
+			t.Errorf("expected ErrDuplicateKey for duplicate role key, got %v", err)
+		}
+
+		editor := &privy.Role{Key: "editor", Name: "Editor", Permissions: []string{"article.update"}, Parents: []string{"viewer"}}
+		if err := storage.CreateRole(editor); err != nil {
+			t.Fatalf("failed to create role: %v", err)
+		}
+
+		if err := storage.CreateRole(&privy.Role{Key: "broken", Parents: []string{"nonexistent"}}); err != privy.ErrRoleNotFound {
+			t.Errorf("expected ErrRoleNotFound for unknown parent, got %v", err)
+		}
+
+		retrieved, err := storage.GetRole("editor")
+		if err != nil {
+			t.Fatalf("failed to get role: %v", err)
+		}
+		if len(retrieved.Permissions) != 1 || retrieved.Permissions[0] != "article.update" {
+			t.Errorf("expected permissions [article.update], got %v", retrieved.Permissions)
+		}
+		if len(retrieved.Parents) != 1 || retrieved.Parents[0] != "viewer" {
+			t.Errorf("expected parents [viewer], got %v", retrieved.Parents)
+		}
+
+		retrieved.Permissions = append(retrieved.Permissions, "article.delete")
+		retrieved.Parents = nil
+		if err := storage.UpdateRole(retrieved); err != nil {
+			t.Fatalf("failed to update role: %v", err)
+		}
+
+		retrieved, err = storage.GetRoleByID(editor.ID)
+		if err != nil {
+			t.Fatalf("failed to get role by ID: %v", err)
+		}
+		if len(retrieved.Permissions) != 2 || len(retrieved.Parents) != 0 {
+			t.Errorf("expected updated role, got %+v", retrieved)
+		}
+
+		roles, err := storage.ListRoles()
+		if err != nil {
+			t.Fatalf("failed to list roles: %v", err)
+		}
+		if len(roles) != 2 {
+			t.Errorf("expected 2 roles, got %d", len(roles))
+		}
+
+		if err := storage.DeleteRole(viewer.ID); err != nil {
+			t.Fatalf("failed to delete role: %v", err)
+		}
+		if _, err := storage.GetRole("viewer"); err != privy.ErrRoleNotFound {
+			t.Errorf("expected ErrRoleNotFound, got %v", err)
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		storage := newStorage(t)
+
+		if _, err := storage.GetResource("nonexistent", nil); err != privy.ErrResourceNotFound {
+			t.Errorf("expected ErrResourceNotFound, got %v", err)
+		}
+		if _, err := storage.GetResourceByID(42); err != privy.ErrResourceNotFound {
+			t.Errorf("expected ErrResourceNotFound, got %v", err)
+		}
+		if _, err := storage.GetAction(42, "read"); err != privy.ErrActionNotFound {
+			t.Errorf("expected ErrActionNotFound, got %v", err)
+		}
+		if _, err := storage.GetRole("nonexistent"); err != privy.ErrRoleNotFound {
+			t.Errorf("expected ErrRoleNotFound, got %v", err)
+		}
+		if _, err := storage.GetRoleByID(42); err != privy.ErrRoleNotFound {
+			t.Errorf("expected ErrRoleNotFound, got %v", err)
+		}
+		if _, err := storage.GetRoleBinding("alice", 42); err != privy.ErrBindingNotFound {
+			t.Errorf("expected ErrBindingNotFound, got %v", err)
+		}
+
+		resources, err := storage.ListResources(nil)
+		if err != nil || len(resources) != 0 {
+			t.Errorf("expected no resources, got %v (%v)", resources, err)
+		}
+		roles, err := storage.ListRoles()
+		if err != nil || len(roles) != 0 {
+			t.Errorf("expected no roles, got %v (%v)", roles, err)
+		}
+	})
+
+	t.Run("MissingReferences", func(t *testing.T) {
+		storage := newStorage(t)
+
+		// Writes referencing missing records must fail even where foreign keys are not enforced
+		missing := uint(999)
+		if err := storage.CreateResource(&privy.Resource{Key: "comment", ParentID: &missing}); err != privy.ErrResourceNotFound {
+			t.Errorf("expected ErrResourceNotFound for missing parent resource, got %v", err)
+		}
+		if err := storage.CreateActions(missing, []privy.Action{{Key: "read"}}); err != privy.ErrResourceNotFound {
+			t.Errorf("expected ErrResourceNotFound for actions of missing resource, got %v", err)
+		}
+		if err := storage.CreateRole(&privy.Role{Key: "editor", Parents: []string{"ghost"}}); err != privy.ErrRoleNotFound {
+			t.Errorf("expected ErrRoleNotFound for missing parent role, got %v", err)
+		}
+		if err := storage.CreateRoleBinding(&privy.RoleBinding{SubjectID: "alice", RoleID: missing}); err != privy.ErrRoleNotFound {
+			t.Errorf("expected ErrRoleNotFound for binding to missing role, got %v", err)
+		}
+
+		article := &privy.Resource{Key: "article", Actions: []privy.Action{{Key: "read"}}}
+		if err := storage.CreateResource(article); err != nil {
+			t.Fatalf("failed to create resource: %v", err)
+		}
+
+		moved := *article
+		moved.ParentID = &missing
+		if err := storage.UpdateResource(&moved); err != privy.ErrResourceNotFound {
+			t.Errorf("expected ErrResourceNotFound for moving resource to missing parent, got %v", err)
+		}
+
+		action, err := storage.GetAction(article.ID, "read")
+		if err != nil {
+			t.Fatalf("failed to get action: %v", err)
+		}
+		action.ResourceID = missing
+		if err := storage.UpdateAction(action); err != privy.ErrResourceNotFound {
+			t.Errorf("expected ErrResourceNotFound for moving action to missing resource, got %v", err)
+		}
+
+		resources, err := storage.ListResources(nil)
+		if err != nil || len(resources) != 1 {
+			t.Errorf("expected only the article resource, got %v (%v)", resources, err)
+		}
+		if actions, err := storage.ListActions(missing); err != nil || len(actions) != 0 {
+			t.Errorf("expected no orphan actions, got %v (%v)", actions, err)
+		}
+		if _, err := storage.GetRole("editor"); err != privy.ErrRoleNotFound {
+			t.Errorf("expected role with missing parent not to be created, got %v", err)
+		}
+		if bindings, err := storage.ListRoleBindings(missing); err != nil || len(bindings) != 0 {
+			t.Errorf("expected no orphan bindings, got %v (%v)", bindings, err)
+		}
+	})
+
+	t.Run("CascadeDeleteResource", func(t *testing.T) {
+		storage := newStorage(t)
+
+		resource := &privy.Resource{
+			Key:     "article",
+			Actions: []privy.Action{{Key: "read"}},
+			SubResources: []privy.Resource{
+				{
+					Key:          "comment",
+					Actions:      []privy.Action{{Key: "delete"}},
+					SubResources: []privy.Resource{{Key: "tag", Actions: []privy.Action{{Key: "assign"}}}},
+				},
+			},
+		}
+		if err := storage.CreateResource(resource); err != nil {
+			t.Fatalf("failed to create resource tree: %v", err)
+		}
+
+		comment := resource.SubResources[0]
+		tag := comment.SubResources[0]
+
+		if err := storage.DeleteResource(resource.ID); err != nil {
+			t.Fatalf("failed to delete resource: %v", err)
+		}
+
+		for _, id := range []uint{resource.ID, comment.ID, tag.ID} {
+			if _, err := storage.GetResourceByID(id); err != privy.ErrResourceNotFound {
+				t.Errorf("expected resource %d to be deleted, got %v", id, err)
+			}
+		}
+
+		for _, id := range []uint{resource.ID, comment.ID, tag.ID} {
+			actions, err := storage.ListActions(id)
+			if err != nil {
+				t.Fatalf("failed to list actions: %v", err)
+			}
+			if len(actions) != 0 {
+				t.Errorf("expected actions of resource %d to be deleted, got %v", id, actions)
+			}
+		}
+
+		// The key can be reused after deletion
+		if err := storage.CreateResource(&privy.Resource{Key: "article"}); err != nil {
+			t.Errorf("failed to recreate resource: %v", err)
+		}
+	})
+
+	t.Run("CascadeDeleteRole", func(t *testing.T) {
+		storage := newStorage(t)
+
+		viewer := &privy.Role{Key: "viewer"}
+		if err := storage.CreateRole(viewer); err != nil {
+			t.Fatalf("failed to create role: %v", err)
+		}
+		editor := &privy.Role{Key: "editor", Parents: []string{"viewer"}}
+		if err := storage.CreateRole(editor); err != nil {
+			t.Fatalf("failed to create role: %v", err)
+		}
+
+		for _, roleID := range []uint{viewer.ID, editor.ID} {
+			if err := storage.CreateRoleBinding(&privy.RoleBinding{SubjectID: "alice", RoleID: roleID}); err != nil {
+				t.Fatalf("failed to create role binding: %v", err)
+			}
+		}
+
+		if err := storage.DeleteRole(viewer.ID); err != nil {
+			t.Fatalf("failed to delete role: %v", err)
+		}
+
+		bindings, err := storage.ListSubjectRoleBindings("alice")
+		if err != nil {
+			t.Fatalf("failed to list role bindings: %v", err)
+		}
+		if len(bindings) != 1 || bindings[0].RoleID != editor.ID {
+			t.Errorf("expected only the editor binding to remain, got %v", bindings)
+		}
+
+		retrieved, err := storage.GetRole("editor")
+		if err != nil {
+			t.Fatalf("failed to get role: %v", err)
+		}
+		if len(retrieved.Parents) != 0 {
+			t.Errorf("expected inheritance edge to be deleted, got %v", retrieved.Parents)
+		}
+	})
+
+	t.Run("RoleBindings", func(t *testing.T) {
+		storage := newStorage(t)
+
+		role := &privy.Role{Key: "editor"}
+		if err := storage.CreateRole(role); err != nil {
+			t.Fatalf("failed to create role: %v", err)
+		}
+
+		binding := &privy.RoleBinding{SubjectID: "alice", RoleID: role.ID}
+		if err := storage.CreateRoleBinding(binding); err != nil {
+			t.Fatalf("failed to create role binding: %v", err)
+		}
+		if binding.ID == 0 {
+			t.Fatal("expected binding ID to be set")
+		}
+
+		if err := storage.CreateRoleBinding(&privy.RoleBinding{SubjectID: "alice", RoleID: role.ID}); !errors.Is(err, privy.ErrDuplicateKey) {
+			t.Errorf("expected ErrDuplicateKey for duplicate role binding, got %v", err)
+		}
+
+		if err := storage.CreateRoleBinding(&privy.RoleBinding{SubjectID: "bob", RoleID: role.ID}); err != nil {
+			t.Fatalf("failed to create role binding: %v", err)
+		}
+
+		bindings, err := storage.ListRoleBindings(role.ID)
+		if err != nil {
+			t.Fatalf("failed to list role bindings: %v", err)
+		}
+		if len(bindings) != 2 {
+			t.Errorf("expected 2 bindings, got %d", len(bindings))
+		}
+
+		if err := storage.DeleteRoleBinding(binding.ID); err != nil {
+			t.Fatalf("failed to delete role binding: %v", err)
+		}
+
+		bindings, err = storage.ListSubjectRoleBindings("alice")
+		if err != nil {
+			t.Fatalf("failed to list subject role bindings: %v", err)
+		}
+		if len(bindings) != 0 {
+			t.Errorf("expected no bindings, got %v", bindings)
+		}
+	})
+
+	t.Run("Transactions", func(t *testing.T) {
+		storage := newStorage(t)
+
+		errRollback := errors.New("rollback")
+		err := storage.WithTx(func(tx privy.Storage) error {
+			resource := &privy.Resource{Key: "article"}
+			if err := tx.CreateResource(resource); err != nil {
+				return err
+			}
+			if err := tx.CreateActions(resource.ID, []privy.Action{{Key: "read"}}); err != nil {
+				return err
+			}
+			if err := tx.CreateRole(&privy.Role{Key: "editor"}); err != nil {
+				return err
+			}
+
+			// Writes are visible inside the transaction
+			if _, err := tx.GetResource("article", nil); err != nil {
+				return err
+			}
+			return errRollback
+		})
+		if err != errRollback {
+			t.Fatalf("expected the callback error, got %v", err)
+		}
+
+		if _, err := storage.GetResource("article", nil); err != privy.ErrResourceNotFound {
+			t.Errorf("expected resource to be rolled back, got %v", err)
+		}
+		if _, err := storage.GetRole("editor"); err != privy.ErrRoleNotFound {
+			t.Errorf("expected role to be rolled back, got %v", err)
+		}
+
+		err = storage.WithTx(func(tx privy.Storage) error {
+			resource := &privy.Resource{Key: "article"}
+			if err := tx.CreateResource(resource); err != nil {
+				return err
+			}
+			return tx.CreateActions(resource.ID, []privy.Action{{Key: "read"}})
+		})
+		if err != nil {
+			t.Fatalf("failed to commit transaction: %v", err)
+		}
+
+		resource, err := storage.GetResource("article", nil)
+		if err != nil {
+			t.Fatalf("expected resource to be committed: %v", err)
+		}
+		if _, err := storage.GetAction(resource.ID, "read"); err != nil {
+			t.Errorf("expected action to be committed: %v", err)
+		}
+	})
+}
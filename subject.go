@@ -0,0 +1,135 @@
+package privy
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrInvalidSubjectID = errors.New("invalid subject id")
+)
+
+// BindRole binds a role to a subject. Binding a role that is already bound is a no-op.
+// It uses context.Background internally; to specify the context, use BindRoleContext.
+func (m *Manager) BindRole(subjectID, roleKey string) error {
+	return m.BindRoleContext(context.Background(), subjectID, roleKey)
+}
+
+// BindRoleContext is like BindRole but runs storage operations with the given context
+func (m *Manager) BindRoleContext(ctx context.Context, subjectID, roleKey string) error {
+	return m.write(ctx, m.subjectChange(AuditBindRole, subjectID), func(s Storage) error {
+		return m.bindRole(s, subjectID, roleKey)
+	})
+}
+
+// bindRole binds a role to a subject unless it is already bound
+func (m *Manager) bindRole(s Storage, subjectID, roleKey string) error {
+	if subjectID == "" {
+		return ErrInvalidSubjectID
+	}
+
+	role, err := s.GetRole(roleKey)
+	if err != nil {
+		return err
+	}
+
+	// Check if binding already exists
+	_, err = s.GetRoleBinding(subjectID, role.ID)
+	if err == nil {
+		return nil
+	}
+	if err != ErrBindingNotFound {
+		return err
+	}
+
+	return s.CreateRoleBinding(&RoleBinding{
+		SubjectID: subjectID,
+		RoleID:    role.ID,
+	})
+}
+
+// UnbindRole removes a role from a subject. Unbinding a role that is not bound is a no-op.
+// It uses context.Background internally; to specify the context, use UnbindRoleContext.
+func (m *Manager) UnbindRole(subjectID, roleKey string) error {
+	return m.UnbindRoleContext(context.Background(), subjectID, roleKey)
+}
+
+// UnbindRoleContext is like UnbindRole but runs storage operations with the given context
+func (m *Manager) UnbindRoleContext(ctx context.Context, subjectID, roleKey string) error {
+	return m.write(ctx, m.subjectChange(AuditUnbindRole, subjectID), func(s Storage) error {
+		return m.unbindRole(s, subjectID, roleKey)
+	})
+}
+
+// unbindRole removes a role from a subject if it is bound
+func (m *Manager) unbindRole(s Storage, subjectID, roleKey string) error {
+	role, err := s.GetRole(roleKey)
+	if err != nil {
+		return err
+	}
+
+	binding, err := s.GetRoleBinding(subjectID, role.ID)
+	if err != nil {
+		if err == ErrBindingNotFound {
+			return nil
+		}
+		return err
+	}
+
+	return s.DeleteRoleBinding(binding.ID)
+}
+
+// ListSubjectRoles lists all roles bound to a subject.
+// It uses context.Background internally; to specify the context, use ListSubjectRolesContext.
+func (m *Manager) ListSubjectRoles(subjectID string) ([]Role, error) {
+	return m.ListSubjectRolesContext(context.Background(), subjectID)
+}
+
+// ListSubjectRolesContext is like ListSubjectRoles but runs storage operations with the given context
+func (m *Manager) ListSubjectRolesContext(ctx context.Context, subjectID string) ([]Role, error) {
+	return m.listSubjectRoles(m.storage.WithContext(ctx), subjectID)
+}
+
+// listSubjectRoles lists all roles bound to a subject, skipping bindings of removed roles
+func (m *Manager) listSubjectRoles(s Storage, subjectID string) ([]Role, error) {
+	bindings, err := s.ListSubjectRoleBindings(subjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := make([]Role, 0, len(bindings))
+	for _, binding := range bindings {
+		role, err := s.GetRoleByID(binding.RoleID)
+		if err != nil {
+			// Skip bindings whose role has been removed
+			if err == ErrRoleNotFound {
+				continue
+			}
+			return nil, err
+		}
+		roles = append(roles, *role)
+	}
+
+	return roles, nil
+}
+
+// Can checks if a subject has the required permission through any of its bound roles.
+// It uses context.Background internally; to specify the context, use CanContext.
+func (m *Manager) Can(subjectID, requiredPermission string) (bool, error) {
+	return m.CanContext(context.Background(), subjectID, requiredPermission)
+}
+
+// CanContext is like Can but runs storage operations with the given context
+func (m *Manager) CanContext(ctx context.Context, subjectID, requiredPermission string) (bool, error) {
+	roles, err := m.ListSubjectRolesContext(ctx, subjectID)
+	if err != nil {
+		return false, err
+	}
+
+	roleKeys := make([]string, 0, len(roles))
+	for _, role := range roles {
+		roleKeys = append(roleKeys, role.Key)
+	}
+
+	return m.CheckRolesPermissionContext(ctx, roleKeys, requiredPermission)
+}
@@ -0,0 +1,185 @@
+package privy
+
+import "testing"
+
+func TestManager_BindRole(t *testing.T) {
+	m := setupTestManager(t)
+
+	_, err := m.CreateRole("editor", RoleConfig{
+		Name:        "Editor",
+		Description: "Can edit and publish articles",
+		Permissions: []string{"article.read", "article.update"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+
+	if err := m.BindRole("alice", "editor"); err != nil {
+		t.Fatalf("failed to bind role: %v", err)
+	}
+
+	// Binding twice should be a no-op
+	if err := m.BindRole("alice", "editor"); err != nil {
+		t.Fatalf("failed to bind role again: %v", err)
+	}
+
+	roles, err := m.ListSubjectRoles("alice")
+	if err != nil {
+		t.Fatalf("failed to list subject roles: %v", err)
+	}
+
+	if len(roles) != 1 {
+		t.Fatalf("expected 1 role, got %d", len(roles))
+	}
+
+	if roles[0].Key != "editor" {
+		t.Errorf("expected role 'editor', got '%s'", roles[0].Key)
+	}
+}
+
+func TestManager_BindRoleErrors(t *testing.T) {
+	m := setupTestManager(t)
+
+	if err := m.BindRole("alice", "nonexistent"); err != ErrRoleNotFound {
+		t.Errorf("expected ErrRoleNotFound, got %v", err)
+	}
+
+	_, err := m.CreateRole("editor", RoleConfig{Name: "Editor"})
+	if err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+
+	if err := m.BindRole("", "editor"); err != ErrInvalidSubjectID {
+		t.Errorf("expected ErrInvalidSubjectID, got %v", err)
+	}
+}
+
+func TestManager_UnbindRole(t *testing.T) {
+	m := setupTestManager(t)
+
+	for _, key := range []string{"editor", "viewer"} {
+		if _, err := m.CreateRole(key, RoleConfig{Name: key}); err != nil {
+			t.Fatalf("failed to create role: %v", err)
+		}
+		if err := m.BindRole("alice", key); err != nil {
+			t.Fatalf("failed to bind role: %v", err)
+		}
+	}
+
+	if err := m.UnbindRole("alice", "editor"); err != nil {
+		t.Fatalf("failed to unbind role: %v", err)
+	}
+
+	// Unbinding a role that is not bound should be a no-op
+	if err := m.UnbindRole("alice", "editor"); err != nil {
+		t.Fatalf("failed to unbind role again: %v", err)
+	}
+
+	roles, err := m.ListSubjectRoles("alice")
+	if err != nil {
+		t.Fatalf("failed to list subject roles: %v", err)
+	}
+
+	if len(roles) != 1 || roles[0].Key != "viewer" {
+		t.Errorf("expected only 'viewer' role, got %v", roles)
+	}
+}
+
+func TestManager_DeleteRoleRemovesBindings(t *testing.T) {
+	m := setupTestManager(t)
+
+	if _, err := m.CreateRole("editor", RoleConfig{Name: "Editor"}); err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+	if err := m.BindRole("alice", "editor"); err != nil {
+		t.Fatalf("failed to bind role: %v", err)
+	}
+
+	if err := m.DeleteRole("editor"); err != nil {
+		t.Fatalf("failed to delete role: %v", err)
+	}
+
+	roles, err := m.ListSubjectRoles("alice")
+	if err != nil {
+		t.Fatalf("failed to list subject roles: %v", err)
+	}
+
+	if len(roles) != 0 {
+		t.Errorf("expected 0 roles, got %d", len(roles))
+	}
+}
+
+func TestManager_Can(t *testing.T) {
+	m := setupTestManager(t)
+
+	_, err := m.CreateRole("editor", RoleConfig{
+		Name:        "Editor",
+		Permissions: []string{"article.read", "article.update"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create editor role: %v", err)
+	}
+
+	_, err = m.CreateRole("moderator", RoleConfig{
+		Name:        "Moderator",
+		Permissions: []string{"article.comment"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create moderator role: %v", err)
+	}
+
+	if err := m.BindRole("alice", "editor"); err != nil {
+		t.Fatalf("failed to bind role: %v", err)
+	}
+	if err := m.BindRole("alice", "moderator"); err != nil {
+		t.Fatalf("failed to bind role: %v", err)
+	}
+	if err := m.BindRole("bob", "editor"); err != nil {
+		t.Fatalf("failed to bind role: %v", err)
+	}
+
+	tests := []struct {
+		name               string
+		subjectID          string
+		requiredPermission string
+		expected           bool
+	}{
+		{
+			name:               "permission from first role",
+			subjectID:          "alice",
+			requiredPermission: "article.update",
+			expected:           true,
+		},
+		{
+			name:               "permission from second role",
+			subjectID:          "alice",
+			requiredPermission: "article.comment.delete",
+			expected:           true,
+		},
+		{
+			name:               "permission not granted",
+			subjectID:          "bob",
+			requiredPermission: "article.comment.delete",
+			expected:           false,
+		},
+		{
+			name:               "subject without bindings",
+			subjectID:          "carol",
+			requiredPermission: "article.read",
+			expected:           false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := m.Can(tt.subjectID, tt.requiredPermission)
+			if err != nil {
+				t.Fatalf("failed to check subject permission: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Can(%q, %q) = %v, want %v",
+					tt.subjectID, tt.requiredPermission, result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package privy
+
+import "context"
+
+const (
+	// SyncCreate means a declared resource or action was missing and has been created
+	SyncCreate = "create"
+
+	// SyncUpdate means the name or description of a resource or action has been updated
+	SyncUpdate = "update"
+
+	// SyncDelete means a resource or action that is no longer declared has been pruned
+	SyncDelete = "delete"
+
+	// SyncResource marks a change to a resource
+	SyncResource = "resource"
+
+	// SyncAction marks a change to an action
+	SyncAction = "action"
+)
+
+// SyncOptions configures SyncResources
+type SyncOptions struct {
+	// Prune deletes resources and actions that exist in storage but are no longer declared.
+	// Top-level resources are pruned too, so every resource of the application must be declared.
+	Prune bool
+}
+
+// SyncChange describes a single change applied by SyncResources
+type SyncChange struct {
+	Op   string `json:"op"`
+	Kind string `json:"kind"`
+	// Path is the resource path, or the permission string for actions, e.g. "article.comment.read"
+	Path string `json:"path"`
+}
+
+// SyncReport lists the changes applied by SyncResources in the order they were made
+type SyncReport struct {
+	Changes []SyncChange `json:"changes"`
+}
+
+func (r *SyncReport) add(op, kind, path string) {
+	r.Changes = append(r.Changes, SyncChange{Op: op, Kind: kind, Path: path})
+}
+
+// SyncResources makes the stored resource tree match the declared one: missing resources and
+// actions are created, changed names and descriptions are updated and, with opts.Prune, resources
+// and actions that are no longer declared are deleted. It is idempotent, so it can run on every
+// deploy, and applies all changes in a single transaction.
+// It uses context.Background internally; to specify the context, use SyncResourcesContext.
+func (m *Manager) SyncResources(configs []ResourceConfig, opts SyncOptions) (*SyncReport, error) {
+	return m.SyncResourcesContext(context.Background(), configs, opts)
+}
+
+// SyncResourcesContext is like SyncResources but runs storage operations with the given context
+func (m *Manager) SyncResourcesContext(ctx context.Context, configs []ResourceConfig, opts SyncOptions) (*SyncReport, error) {
+	trees := make([]Resource, 0, len(configs))
+	for _, config := range configs {
+		trees = append(trees, Resource{
+			Key:          config.Key,
+			Name:         config.Name,
+			Description:  config.Description,
+			Actions:      config.Actions,
+			SubResources: config.SubResources,
+		})
+	}
+
+	if err := validateSubResources("", trees); err != nil {
+		return nil, err
+	}
+
+	c := change{
+		operation:  AuditSyncResources,
+		targetType: AuditTargetResource,
+		snapshot: func(s Storage) (any, error) {
+			return exportResources(s, nil)
+		},
+	}
+
+	return writeResult(ctx, m, c, func(s Storage) (*SyncReport, error) {
+		report := &SyncReport{Changes: make([]SyncChange, 0)}
+		return report, m.syncResources(s, nil, "", trees, opts, report)
+	})
+}
+
+// syncResources syncs the declared resources under a parent, or top-level resources if parentID is nil
+func (m *Manager) syncResources(s Storage, parentID *uint, parentPath string, configs []Resource, opts SyncOptions, report *SyncReport) error {
+	declared := make(map[string]bool)
+	for _, config := range configs {
+		declared[config.Key] = true
+
+		path := config.Key
+		if parentPath != "" {
+			path = parentPath + "." + config.Key
+		}
+
+		existing, err := s.GetResource(config.Key, parentID)
+		if err != nil && err != ErrResourceNotFound {
+			return err
+		}
+
+		if existing == nil {
+			if _, err := m.createResourceTree(s, config, parentID); err != nil {
+				return err
+			}
+			reportResourceTree(report, path, config)
+			continue
+		}
+
+		if existing.Name != config.Name || existing.Description != config.Description {
+			updated := *existing
+			updated.Name = config.Name
+			updated.Description = config.Description
+			updated.Actions = nil
+			updated.SubResources = nil
+
+			if err := s.UpdateResource(&updated); err != nil {
+				return err
+			}
+			report.add(SyncUpdate, SyncResource, path)
+		}
+
+		if err := m.syncActions(s, existing, path, config.Actions, opts, report); err != nil {
+			return err
+		}
+
+		if err := m.syncResources(s, &existing.ID, path, config.SubResources, opts, report); err != nil {
+			return err
+		}
+	}
+
+	if !opts.Prune {
+		return nil
+	}
+
+	stored, err := s.ListResources(parentID)
+	if err != nil {
+		return err
+	}
+
+	for _, resource := range stored {
+		if declared[resource.Key] {
+			continue
+		}
+
+		// Deleting a resource also deletes its actions and sub-resources
+		if err := s.DeleteResource(resource.ID); err != nil {
+			return err
+		}
+
+		path := resource.Key
+		if parentPath != "" {
+			path = parentPath + "." + resource.Key
+		}
+		report.add(SyncDelete, SyncResource, path)
+	}
+
+	return nil
+}
+
+// syncActions syncs the declared actions of an existing resource
+func (m *Manager) syncActions(s Storage, resource *Resource, path string, actions []Action, opts SyncOptions, report *SyncReport) error {
+	stored := make(map[string]Action, len(resource.Actions))
+	for _, action := range resource.Actions {
+		stored[action.Key] = action
+	}
+
+	declared := make(map[string]bool, len(actions))
+	for _, action := range actions {
+		declared[action.Key] = true
+
+		existing, ok := stored[action.Key]
+		if !ok {
+			if err := s.CreateActions(resource.ID, declaredActions([]Action{action})); err != nil {
+				return err
+			}
+			report.add(SyncCreate, SyncAction, BuildPermissionString(path, action.Key))
+			continue
+		}
+
+		if existing.Name != action.Name || existing.Description != action.Description {
+			existing.Name = action.Name
+			existing.Description = action.Description
+
+			if err := s.UpdateAction(&existing); err != nil {
+				return err
+			}
+			report.add(SyncUpdate, SyncAction, BuildPermissionString(path, action.Key))
+		}
+	}
+
+	if !opts.Prune {
+		return nil
+	}
+
+	for _, action := range resource.Actions {
+		if declared[action.Key] {
+			continue
+		}
+
+		if err := s.DeleteAction(action.ID); err != nil {
+			return err
+		}
+		report.add(SyncDelete, SyncAction, BuildPermissionString(path, action.Key))
+	}
+
+	return nil
+}
+
+// reportResourceTree records the creation of a resource with its actions and sub-resources
+func reportResourceTree(report *SyncReport, path string, config Resource) {
+	report.add(SyncCreate, SyncResource, path)
+
+	for _, action := range config.Actions {
+		report.add(SyncCreate, SyncAction, BuildPermissionString(path, action.Key))
+	}
+
+	for _, sub := range config.SubResources {
+		reportResourceTree(report, path+"."+sub.Key, sub)
+	}
+}
@@ -0,0 +1,159 @@
+package privy
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func syncTestConfigs() []ResourceConfig {
+	return []ResourceConfig{
+		{
+			Key:     "article",
+			Name:    "Article",
+			Actions: []Action{DefineAction("read", "Read", "Read article content")},
+			SubResources: []Resource{
+				{
+					Key:     "comment",
+					Name:    "Comment",
+					Actions: []Action{DefineAction("create", "Create", "Create a comment")},
+				},
+			},
+		},
+	}
+}
+
+func TestManager_SyncResources(t *testing.T) {
+	m := setupTestManager(t)
+
+	report, err := m.SyncResources(syncTestConfigs(), SyncOptions{})
+	if err != nil {
+		t.Fatalf("failed to sync resources: %v", err)
+	}
+
+	expected := []SyncChange{
+		{Op: SyncCreate, Kind: SyncResource, Path: "article"},
+		{Op: SyncCreate, Kind: SyncAction, Path: "article.read"},
+		{Op: SyncCreate, Kind: SyncResource, Path: "article.comment"},
+		{Op: SyncCreate, Kind: SyncAction, Path: "article.comment.create"},
+	}
+	if !reflect.DeepEqual(report.Changes, expected) {
+		t.Errorf("expected changes %v, got %v", expected, report.Changes)
+	}
+
+	// Syncing the same tree again is a no-op
+	report, err = m.SyncResources(syncTestConfigs(), SyncOptions{})
+	if err != nil {
+		t.Fatalf("failed to sync resources: %v", err)
+	}
+	if len(report.Changes) != 0 {
+		t.Errorf("expected no changes, got %v", report.Changes)
+	}
+
+	configs := syncTestConfigs()
+	configs[0].Description = "News article entity"
+	configs[0].Actions = append(configs[0].Actions, DefineAction("update", "Update", "Edit existing article"))
+	configs[0].SubResources[0].Actions[0].Name = "Create Comment"
+
+	report, err = m.SyncResources(configs, SyncOptions{})
+	if err != nil {
+		t.Fatalf("failed to sync resources: %v", err)
+	}
+
+	expected = []SyncChange{
+		{Op: SyncUpdate, Kind: SyncResource, Path: "article"},
+		{Op: SyncCreate, Kind: SyncAction, Path: "article.update"},
+		{Op: SyncUpdate, Kind: SyncAction, Path: "article.comment.create"},
+	}
+	if !reflect.DeepEqual(report.Changes, expected) {
+		t.Errorf("expected changes %v, got %v", expected, report.Changes)
+	}
+
+	r, err := m.GetResource("article")
+	if err != nil {
+		t.Fatalf("failed to get resource: %v", err)
+	}
+	if r.Description != "News article entity" {
+		t.Errorf("expected updated description, got '%s'", r.Description)
+	}
+	if len(r.Actions) != 2 {
+		t.Errorf("expected 2 actions, got %d", len(r.Actions))
+	}
+
+	comment, err := m.GetResource("article.comment")
+	if err != nil {
+		t.Fatalf("failed to get resource: %v", err)
+	}
+	if len(comment.Actions) != 1 || comment.Actions[0].Name != "Create Comment" {
+		t.Errorf("expected updated action name, got %v", comment.Actions)
+	}
+}
+
+func TestManager_SyncResourcesPrune(t *testing.T) {
+	m := setupTestManager(t)
+
+	configs := syncTestConfigs()
+	configs[0].Actions = append(configs[0].Actions, DefineAction("update", "Update", "Edit existing article"))
+	configs = append(configs, ResourceConfig{Key: "user", Name: "User"})
+
+	if _, err := m.SyncResources(configs, SyncOptions{}); err != nil {
+		t.Fatalf("failed to sync resources: %v", err)
+	}
+
+	// Without pruning, undeclared resources and actions are kept
+	report, err := m.SyncResources(syncTestConfigs(), SyncOptions{})
+	if err != nil {
+		t.Fatalf("failed to sync resources: %v", err)
+	}
+	if len(report.Changes) != 0 {
+		t.Errorf("expected no changes, got %v", report.Changes)
+	}
+
+	report, err = m.SyncResources(syncTestConfigs(), SyncOptions{Prune: true})
+	if err != nil {
+		t.Fatalf("failed to sync resources: %v", err)
+	}
+
+	expected := []SyncChange{
+		{Op: SyncDelete, Kind: SyncAction, Path: "article.update"},
+		{Op: SyncDelete, Kind: SyncResource, Path: "user"},
+	}
+	if !reflect.DeepEqual(report.Changes, expected) {
+		t.Errorf("expected changes %v, got %v", expected, report.Changes)
+	}
+
+	if _, err := m.GetResource("user"); err != ErrResourceNotFound {
+		t.Errorf("expected ErrResourceNotFound, got %v", err)
+	}
+
+	r, err := m.GetResource("article")
+	if err != nil {
+		t.Fatalf("failed to get resource: %v", err)
+	}
+	if len(r.Actions) != 1 || r.Actions[0].Key != "read" {
+		t.Errorf("expected only 'read' action to be left, got %v", r.Actions)
+	}
+}
+
+func TestManager_SyncResourcesInvalid(t *testing.T) {
+	m := setupTestManager(t)
+
+	configs := append(syncTestConfigs(), ResourceConfig{Key: "article"})
+	if _, err := m.SyncResources(configs, SyncOptions{}); !errors.Is(err, ErrDuplicateKey) {
+		t.Errorf("expected ErrDuplicateKey, got %v", err)
+	}
+
+	configs = syncTestConfigs()
+	configs[0].SubResources[0].Key = "comment.tag"
+	if _, err := m.SyncResources(configs, SyncOptions{}); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("expected ErrInvalidKey, got %v", err)
+	}
+
+	resources, err := m.ListResources()
+	if err != nil {
+		t.Fatalf("failed to list resources: %v", err)
+	}
+	if len(resources) != 0 {
+		t.Errorf("expected no resources to be created, got %d", len(resources))
+	}
+}
@@ -51,6 +51,7 @@ type Role struct {
 	Name        string    `json:"name"`
 	Description string    `json:"description"`
 	Permissions []string  `gorm:"serializer:json" json:"permissions"`
+	Parents     []string  `gorm:"-" json:"parents"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
@@ -60,4 +61,21 @@ type RoleConfig struct {
 	Name        string
 	Description string
 	Permissions []string
+	Parents     []string
+}
+
+// RoleParent is an inheritance edge between a role and one of its parent roles
+type RoleParent struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	RoleID    uint      `gorm:"uniqueIndex:idx_role_parent;not null" json:"role_id"`
+	ParentID  uint      `gorm:"uniqueIndex:idx_role_parent;index;not null" json:"parent_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RoleBinding binds a role to a subject (e.g. a user or a service account)
+type RoleBinding struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	SubjectID string    `gorm:"uniqueIndex:idx_subject_role;not null" json:"subject_id"`
+	RoleID    uint      `gorm:"uniqueIndex:idx_subject_role;index;not null" json:"role_id"`
+	CreatedAt time.Time `json:"created_at"`
 }
@@ -0,0 +1,109 @@
+package privy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrUnknownPermission = errors.New("unknown permission")
+)
+
+const (
+	// UnknownResource means a permission segment does not resolve to a registered resource
+	UnknownResource = "resource"
+
+	// UnknownAction means the last permission segment is neither a sub-resource nor an action
+	UnknownAction = "action"
+)
+
+// UnknownPermission describes a permission that does not resolve to registered resources and actions
+type UnknownPermission struct {
+	Permission string `json:"permission"`
+	Segment    string `json:"segment"`
+	Kind       string `json:"kind"`
+}
+
+// UnknownPermissionsError lists every permission that failed registry validation.
+// It matches ErrUnknownPermission with errors.Is.
+type UnknownPermissionsError struct {
+	Unknown []UnknownPermission `json:"unknown"`
+}
+
+func (e *UnknownPermissionsError) Error() string {
+	problems := make([]string, 0, len(e.Unknown))
+	for _, u := range e.Unknown {
+		problems = append(problems, fmt.Sprintf("%q (unknown %s %q)", u.Permission, u.Kind, u.Segment))
+	}
+	return fmt.Sprintf("%s: %s", ErrUnknownPermission, strings.Join(problems, ", "))
+}
+
+func (e *UnknownPermissionsError) Unwrap() error {
+	return ErrUnknownPermission
+}
+
+// validateRegisteredPermissions resolves each permission against the registered resources and
+// actions when registry validation is enabled. Group grants resolve to resources, and resolution
+// stops at the first wildcard segment.
+func (m *Manager) validateRegisteredPermissions(s Storage, permissions []string) error {
+	if !m.permissionValidation {
+		return nil
+	}
+
+	var unknown []UnknownPermission
+	for _, p := range permissions {
+		u, err := m.resolveRegisteredPermission(s, p)
+		if err != nil {
+			return err
+		}
+		if u != nil {
+			unknown = append(unknown, *u)
+		}
+	}
+
+	if len(unknown) > 0 {
+		return &UnknownPermissionsError{Unknown: unknown}
+	}
+
+	return nil
+}
+
+// resolveRegisteredPermission walks the resource tree along the permission segments.
+// It returns a description of the first segment that cannot be resolved, or nil.
+func (m *Manager) resolveRegisteredPermission(s Storage, permission string) (*UnknownPermission, error) {
+	segments := parsePermission(strings.TrimPrefix(permission, DenyPrefix))
+
+	var parentID *uint
+	for i, segment := range segments {
+		if segment == WildcardSegment || segment == RecursiveWildcardSegment {
+			return nil, nil
+		}
+
+		resource, err := s.GetResource(segment, parentID)
+		if err == nil {
+			parentID = &resource.ID
+			continue
+		}
+		if err != ErrResourceNotFound {
+			return nil, err
+		}
+
+		// The last segment may be an action of the resource resolved so far
+		if i == len(segments)-1 && parentID != nil {
+			_, err := s.GetAction(*parentID, segment)
+			if err == nil {
+				return nil, nil
+			}
+			if err != ErrActionNotFound {
+				return nil, err
+			}
+
+			return &UnknownPermission{Permission: permission, Segment: segment, Kind: UnknownAction}, nil
+		}
+
+		return &UnknownPermission{Permission: permission, Segment: segment, Kind: UnknownResource}, nil
+	}
+
+	return nil, nil
+}
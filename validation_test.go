@@ -0,0 +1,112 @@
+package privy
+
+import (
+	"errors"
+	"testing"
+)
+
+func setupValidationManager(t *testing.T) *Manager {
+	m := setupTestManager(t, WithPermissionValidation(true))
+
+	_, err := m.CreateResource(ResourceConfig{
+		Key:  "article",
+		Name: "Article",
+		Actions: []Action{
+			DefineAction("read", "Read", "Read article content"),
+			DefineAction("delete", "Delete", "Delete article"),
+		},
+		SubResources: []Resource{
+			{
+				Key:  "comment",
+				Name: "Comment",
+				Actions: []Action{
+					DefineAction("read", "Read Comment", "Read comment content"),
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create resource: %v", err)
+	}
+
+	return m
+}
+
+func TestManager_PermissionValidation(t *testing.T) {
+	m := setupValidationManager(t)
+
+	tests := []struct {
+		name        string
+		permissions []string
+		valid       bool
+	}{
+		{"action", []string{"article.read"}, true},
+		{"sub-resource action", []string{"article.comment.read"}, true},
+		{"resource group", []string{"article"}, true},
+		{"sub-resource group", []string{"article.comment"}, true},
+		{"wildcard", []string{"*"}, true},
+		{"segment wildcard", []string{"article.*.read", "*.read"}, true},
+		{"deny entry", []string{"article", "!article.delete"}, true},
+		{"unknown resource", []string{"artcle.read"}, false},
+		{"unknown action", []string{"article.raed"}, false},
+		{"unknown sub-resource", []string{"article.tag.assign"}, false},
+		{"unknown deny", []string{"!article.purge"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := m.CreateRole(tt.name, RoleConfig{
+				Name:        tt.name,
+				Permissions: tt.permissions,
+			})
+			if tt.valid && err != nil {
+				t.Errorf("expected permissions %v to be valid, got %v", tt.permissions, err)
+			}
+			if !tt.valid && !errors.Is(err, ErrUnknownPermission) {
+				t.Errorf("expected ErrUnknownPermission for %v, got %v", tt.permissions, err)
+			}
+		})
+	}
+}
+
+func TestManager_PermissionValidationReportsAllProblems(t *testing.T) {
+	m := setupValidationManager(t)
+
+	if _, err := m.CreateRole("editor", RoleConfig{Name: "Editor"}); err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+
+	err := m.AssignPermissions("editor", []string{"artcle.read", "article.read", "article.comment.edit"})
+
+	var unknownErr *UnknownPermissionsError
+	if !errors.As(err, &unknownErr) {
+		t.Fatalf("expected *UnknownPermissionsError, got %v", err)
+	}
+
+	expected := []UnknownPermission{
+		{Permission: "artcle.read", Segment: "artcle", Kind: UnknownResource},
+		{Permission: "article.comment.edit", Segment: "edit", Kind: UnknownAction},
+	}
+
+	if len(unknownErr.Unknown) != len(expected) {
+		t.Fatalf("expected %d problems, got %v", len(expected), unknownErr.Unknown)
+	}
+
+	for i, u := range unknownErr.Unknown {
+		if u != expected[i] {
+			t.Errorf("expected problem %v, got %v", expected[i], u)
+		}
+	}
+}
+
+func TestManager_PermissionValidationDisabled(t *testing.T) {
+	m := setupTestManager(t)
+
+	_, err := m.CreateRole("editor", RoleConfig{
+		Name:        "Editor",
+		Permissions: []string{"artcle.read"},
+	})
+	if err != nil {
+		t.Errorf("expected unknown permissions to be accepted by default, got %v", err)
+	}
+}